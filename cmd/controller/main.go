@@ -8,31 +8,64 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/pprof"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/pflag"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
 
 	"github.com/sap/clustersecret-operator/internal/controller"
+	"github.com/sap/clustersecret-operator/internal/logging"
+	"github.com/sap/clustersecret-operator/internal/metrics"
+	"github.com/sap/clustersecret-operator/internal/tracing"
 
 	coreclients "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned"
 )
 
+// prefix of the environment variables which may be used instead of certain command line flags
+const envPrefix = "CLUSTERSECRET_OPERATOR_"
+
 var (
-	kubeconfig     string
-	leaseNamespace string
-	leaseName      string
-	leaseId        string
+	kubeconfig             string
+	leaseNamespace         string
+	leaseName              string
+	leaseId                string
+	metricsAddress         string
+	probeAddress           string
+	livenessWindow         time.Duration
+	debugAddress           string
+	eventVerbosity         string
+	revisionNamespace      string
+	revisionHistoryLimit   int
+	auditInterval          time.Duration
+	selfHealing            bool
+	secretWriteConcurrency int
+	workers                int
+	resyncPeriod           time.Duration
+	baseBackoff            time.Duration
+	maxBackoff             time.Duration
+	bucketQPS              float64
+	bucketBurst            int
+	clientQPS              float32
+	clientBurst            int
+	logFormat              string
+	otlpEndpoint           string
+	otlpInsecure           bool
 )
 
 func main() {
@@ -44,22 +77,52 @@ func main() {
 	pflag.StringVar(&leaseNamespace, "lease_namespace", "", "Lease namespace. Required if running out-of-cluster; otherwise defaults to controller's namespace")
 	pflag.StringVar(&leaseName, "lease_name", "", "Lease name. Required")
 	pflag.StringVar(&leaseId, "lease_id", "", "Lease ID. Optional; if unspecified, a unique ID will be generated")
-
+	pflag.StringVar(&metricsAddress, "metrics_bind_address", "", "Bind address of the metrics endpoint (e.g. :8080). Optional; if unspecified, metrics are not served")
+	pflag.StringVar(&probeAddress, "probe_bind_address", "", "Bind address of the health probe endpoints /healthz and /readyz (e.g. :8081). Optional; if unspecified, probes are not served")
+	pflag.DurationVar(&livenessWindow, "liveness_window", 5*time.Minute, "Liveness probe fails if no worker made progress within this time window while the queue is not empty")
+	pflag.StringVar(&debugAddress, "debug_bind_address", "", "Bind address of the debug endpoints /debug/controller and /debug/pprof/ (e.g. 127.0.0.1:6060). Optional; if unspecified, debug endpoints are not served")
+	pflag.StringVar(&eventVerbosity, "event_verbosity", string(controller.EventVerbositySummary), "Event verbosity (one of warning, summary, detailed)")
+	pflag.StringVar(&revisionNamespace, "revision_namespace", "", "Namespace where revisions of clustersecret templates are stored. Optional; if unspecified, no revisions are recorded")
+	pflag.IntVar(&revisionHistoryLimit, "revision_history_limit", 10, "Number of revisions kept per clustersecret")
+	pflag.DurationVar(&auditInterval, "audit_interval", 0, "Interval in which managed secrets are audited for drift (i.e. modifications by someone else). Optional; if zero, no audits are performed")
+	pflag.BoolVar(&selfHealing, "self_healing", false, "Immediately repair managed secrets which are modified or deleted by someone else")
+	pflag.IntVar(&secretWriteConcurrency, "secret_write_concurrency", 10, "Maximum number of secrets written in parallel by one reconciliation of a clustersecret")
+	pflag.IntVar(&workers, "workers", 3, "Number of worker routines")
+	pflag.DurationVar(&resyncPeriod, "resync_period", 5*time.Minute, "Resync period of the informers")
+	pflag.DurationVar(&baseBackoff, "base_backoff", 5*time.Millisecond, "Base delay of the per-item exponential backoff when requeuing failed items")
+	pflag.DurationVar(&maxBackoff, "max_backoff", 1000*time.Second, "Maximum delay of the per-item exponential backoff when requeuing failed items")
+	pflag.Float64Var(&bucketQPS, "bucket_qps", 10, "Overall rate (per second) at which failed items are requeued")
+	pflag.IntVar(&bucketBurst, "bucket_burst", 100, "Overall burst of requeues of failed items")
+	pflag.Float32Var(&clientQPS, "client_qps", 20, "Maximum rate (per second) of requests to the Kubernetes API server")
+	pflag.IntVar(&clientBurst, "client_burst", 30, "Maximum burst of requests to the Kubernetes API server")
+	pflag.StringVar(&logFormat, "log_format", logging.FormatText, "Log format (one of text, json)")
+	pflag.StringVar(&otlpEndpoint, "otlp_endpoint", "", "OTLP (gRPC) endpoint (host:port) to export traces to. Optional; if unspecified, tracing is disabled")
+	pflag.BoolVar(&otlpInsecure, "otlp_insecure", false, "Disable TLS for the connection to the OTLP endpoint")
 	klog.InitFlags(nil)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.CommandLine.SortFlags = false
 	pflag.Parse()
 
+	// setup logging
+	if err := logging.SetFormat(logFormat); err != nil {
+		errlog.Fatalf("flag --log_format invalid: %s", err)
+	}
+
 	// check if running in-cluster or out-of-cluster
 	inCluster, namespace, err := checkIfRunningInCluster()
 	if err != nil {
-		klog.Fatalf("error checking whether running in-cluster or out-of-cluster: %s", err)
+		logging.Fatal(err, "error checking whether running in-cluster or out-of-cluster")
 	}
 
 	// use fallback from environment for certain flags
 	if kubeconfig == "" {
 		kubeconfig = os.Getenv("KUBECONFIG")
 	}
+	for _, name := range []string{"workers", "resync_period", "base_backoff", "max_backoff", "bucket_qps", "bucket_burst", "client_qps", "client_burst"} {
+		if err := setFlagFromEnv(name); err != nil {
+			errlog.Fatal(err)
+		}
+	}
 
 	// check/default flags
 	if inCluster && kubeconfig != "" {
@@ -77,28 +140,126 @@ func main() {
 	if leaseId == "" {
 		leaseId = uuid.New().String()
 	}
+	parsedEventVerbosity, err := controller.ParseEventVerbosity(eventVerbosity)
+	if err != nil {
+		errlog.Fatalf("flag --event_verbosity invalid: %s", err)
+	}
+	if revisionHistoryLimit <= 0 {
+		errlog.Fatal("flag --revision_history_limit must be positive")
+	}
+	if secretWriteConcurrency <= 0 {
+		errlog.Fatal("flag --secret_write_concurrency must be positive")
+	}
+	if workers <= 0 {
+		errlog.Fatal("flag --workers must be positive")
+	}
+	if resyncPeriod <= 0 {
+		errlog.Fatal("flag --resync_period must be positive")
+	}
+	if baseBackoff <= 0 {
+		errlog.Fatal("flag --base_backoff must be positive")
+	}
+	if maxBackoff < baseBackoff {
+		errlog.Fatal("flag --max_backoff must not be less than --base_backoff")
+	}
+	if bucketQPS <= 0 {
+		errlog.Fatal("flag --bucket_qps must be positive")
+	}
+	if bucketBurst <= 0 {
+		errlog.Fatal("flag --bucket_burst must be positive")
+	}
+	if clientQPS <= 0 {
+		errlog.Fatal("flag --client_qps must be positive")
+	}
+	if clientBurst <= 0 {
+		errlog.Fatal("flag --client_burst must be positive")
+	}
+
+	// setup metrics (if requested); note: this must happen before the api clients and the controller (including its workqueue) are created
+	if metricsAddress != "" {
+		metrics.Setup()
+	}
+
+	// setup tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), "clustersecret-operator-controller", otlpEndpoint, otlpInsecure)
+	if err != nil {
+		logging.Fatal(err, "error setting up tracing")
+	}
+	defer shutdownTracing(context.Background())
 
 	// setup api clients
 	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
 		errlog.Fatalf("error building kubeconfig: %s", err)
 	}
+	cfg.QPS = clientQPS
+	cfg.Burst = clientBurst
+	tracing.WrapConfig(cfg)
 
 	kubeclient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		klog.Fatalf("error building kubernetes client: %s", err)
+		logging.Fatal(err, "error building kubernetes client")
+	}
+
+	metadataclient, err := metadata.NewForConfig(cfg)
+	if err != nil {
+		logging.Fatal(err, "error building metadata client")
 	}
 
 	coreclient, err := coreclients.NewForConfig(cfg)
 	if err != nil {
-		klog.Fatalf("error building core client: %s", err)
+		logging.Fatal(err, "error building core client")
 	}
 
 	// create main context
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// create controller
-	controller := controller.NewController(ctx, kubeclient, coreclient, nil)
+	controller := controller.NewController(ctx, kubeclient, metadataclient, coreclient, nil, controller.Options{
+		EventVerbosity:         parsedEventVerbosity,
+		RevisionNamespace:      revisionNamespace,
+		RevisionHistoryLimit:   revisionHistoryLimit,
+		AuditInterval:          auditInterval,
+		SelfHealing:            selfHealing,
+		SecretWriteConcurrency: secretWriteConcurrency,
+		Workers:                workers,
+		ResyncPeriod:           resyncPeriod,
+		BaseBackoff:            baseBackoff,
+		MaxBackoff:             maxBackoff,
+		BucketQPS:              bucketQPS,
+		BucketBurst:            bucketBurst,
+	})
+
+	// serve metrics, probes and debug endpoints (if requested); endpoints with the same bind address share one listener
+	muxes := make(map[string]*http.ServeMux)
+	handle := func(address string, pattern string, handler http.Handler) {
+		if _, ok := muxes[address]; !ok {
+			muxes[address] = http.NewServeMux()
+		}
+		muxes[address].Handle(pattern, handler)
+	}
+	if metricsAddress != "" {
+		metrics.Registry.MustRegister(controller.MetricsCollector())
+		handle(metricsAddress, "/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+	}
+	if probeAddress != "" {
+		handle(probeAddress, "/healthz", controller.HealthzHandler(livenessWindow))
+		handle(probeAddress, "/readyz", controller.ReadyzHandler())
+	}
+	if debugAddress != "" {
+		handle(debugAddress, "/debug/controller", controller.DebugHandler())
+		handle(debugAddress, "/debug/pprof/", http.HandlerFunc(pprof.Index))
+		handle(debugAddress, "/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+		handle(debugAddress, "/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+		handle(debugAddress, "/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+		handle(debugAddress, "/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	}
+	for address, mux := range muxes {
+		go func(address string, mux *http.ServeMux) {
+			klog.InfoS("starting http listener", "address", address)
+			logging.Fatal(http.ListenAndServe(address, mux), "error running http listener", "address", address)
+		}(address, mux)
+	}
 
 	// trying to become leader
 	leaderelection.RunOrDie(
@@ -114,17 +275,19 @@ func main() {
 					Identity: leaseId,
 				},
 			},
+			// note: the name is used as label value of the leader election metrics
+			Name:            leaseName,
 			ReleaseOnCancel: false,
 			LeaseDuration:   15 * time.Second,
 			RenewDeadline:   10 * time.Second,
 			RetryPeriod:     2 * time.Second,
 			Callbacks: leaderelection.LeaderCallbacks{
 				OnStartedLeading: func(ctx context.Context) {
-					klog.Infof("successfully acquired leadership (my id: %s); starting controller", leaseId)
+					klog.InfoS("successfully acquired leadership; starting controller", "id", leaseId)
 					controller.Start()
 				},
 				OnStoppedLeading: func() {
-					klog.Infof("stopped leading (my id: %s)", leaseId)
+					klog.InfoS("stopped leading", "id", leaseId)
 					cancel()
 					controller.Wait()
 				},
@@ -132,14 +295,28 @@ func main() {
 					if identity == leaseId {
 						return
 					}
-					klog.Infof("observed new leader (my id: %s, new leader id: %s); waiting to become leader", leaseId, identity)
+					klog.InfoS("observed new leader; waiting to become leader", "id", leaseId, "leader", identity)
 				},
 			},
 		},
 	)
 
 	// exit
-	klog.Info("exiting")
+	klog.InfoS("exiting")
+}
+
+// set the given flag from the according environment variable (the flag name in upper case, prefixed with envPrefix),
+// unless the flag was specified on the command line
+func setFlagFromEnv(name string) error {
+	key := envPrefix + strings.ToUpper(name)
+	value, ok := os.LookupEnv(key)
+	if !ok || pflag.CommandLine.Changed(name) {
+		return nil
+	}
+	if err := pflag.CommandLine.Set(name, value); err != nil {
+		return fmt.Errorf("environment variable %s invalid: %s", key, err)
+	}
+	return nil
 }
 
 func checkIfRunningInCluster() (bool, string, error) {
@@ -6,6 +6,7 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
@@ -13,16 +14,25 @@ import (
 
 	"github.com/spf13/pflag"
 
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 
 	"github.com/sap/clustersecret-operator/internal/admission"
+	"github.com/sap/clustersecret-operator/internal/logging"
+	"github.com/sap/clustersecret-operator/internal/tracing"
+
+	coreclients "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned"
 )
 
 var (
-	bindAddress string
-	tlsEnabled  bool
-	tlsKeyFile  string
-	tlsCertFile string
+	kubeconfig   string
+	bindAddress  string
+	tlsEnabled   bool
+	tlsKeyFile   string
+	tlsCertFile  string
+	logFormat    string
+	otlpEndpoint string
+	otlpInsecure bool
 )
 
 func main() {
@@ -30,15 +40,24 @@ func main() {
 	errlog := log.New(os.Stderr, "", 0)
 
 	// parse flags
+	pflag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if running out-of-cluster")
 	pflag.StringVar(&bindAddress, "bind_address", ":1080", "Bind address")
 	pflag.BoolVar(&tlsEnabled, "tls_enabled", false, "Enable TlS")
 	pflag.StringVar(&tlsKeyFile, "tls_key_file", "", "Path to TLS key")
 	pflag.StringVar(&tlsCertFile, "tls_cert_file", "", "Path to TLS certificate")
+	pflag.StringVar(&logFormat, "log_format", logging.FormatText, "Log format (one of text, json)")
+	pflag.StringVar(&otlpEndpoint, "otlp_endpoint", "", "OTLP (gRPC) endpoint (host:port) to export traces to. Optional; if unspecified, tracing is disabled")
+	pflag.BoolVar(&otlpInsecure, "otlp_insecure", false, "Disable TLS for the connection to the OTLP endpoint")
 	klog.InitFlags(nil)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.CommandLine.SortFlags = false
 	pflag.Parse()
 
+	// setup logging
+	if err := logging.SetFormat(logFormat); err != nil {
+		errlog.Fatalf("flag --log_format invalid: %s", err)
+	}
+
 	if tlsEnabled {
 		if tlsKeyFile == "" {
 			errlog.Fatal("flag --tls_key_file is required")
@@ -48,15 +67,42 @@ func main() {
 		}
 	}
 
+	// use fallback from environment for certain flags
+	if kubeconfig == "" {
+		kubeconfig = os.Getenv("KUBECONFIG")
+	}
+
+	// setup tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), "clustersecret-operator-webhook", otlpEndpoint, otlpInsecure)
+	if err != nil {
+		logging.Fatal(err, "error setting up tracing")
+	}
+	defer shutdownTracing(context.Background())
+
+	// setup api clients
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		errlog.Fatalf("error building kubeconfig: %s", err)
+	}
+	tracing.WrapConfig(cfg)
+
+	coreclient, err := coreclients.NewForConfig(cfg)
+	if err != nil {
+		logging.Fatal(err, "error building core client")
+	}
+
 	// start webhooks
-	klog.Infof("starting webhook on %s (TLS enabled: %v)", bindAddress, tlsEnabled)
-	admissionHandler := admission.NewHandler()
+	admissionHandler := admission.NewHandler(coreclient)
+	if err := admissionHandler.Start(context.Background()); err != nil {
+		logging.Fatal(err, "error starting admission handler")
+	}
+	klog.InfoS("starting webhook", "address", bindAddress, "tls", tlsEnabled)
 	http.HandleFunc("/healthz", func(http.ResponseWriter, *http.Request) {})
 	http.HandleFunc("/validation", admissionHandler.Validate)
 	http.HandleFunc("/mutation", admissionHandler.Mutate)
 	if tlsEnabled {
-		klog.Fatalf("error running http listener: %s", http.ListenAndServeTLS(bindAddress, tlsCertFile, tlsKeyFile, nil))
+		logging.Fatal(http.ListenAndServeTLS(bindAddress, tlsCertFile, tlsKeyFile, nil), "error running http listener")
 	} else {
-		klog.Fatalf("error running http listener: %s", http.ListenAndServe(bindAddress, nil))
+		logging.Fatal(http.ListenAndServe(bindAddress, nil), "error running http listener")
 	}
 }
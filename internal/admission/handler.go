@@ -6,20 +6,56 @@ SPDX-License-Identifier: Apache-2.0
 package admission
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
 	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/klog/v2"
+
+	"github.com/sap/clustersecret-operator/internal/tracing"
+
+	coreclients "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned"
+	coreinformers "github.com/sap/clustersecret-operator/pkg/client/informers/externalversions"
+	corev1alpha1listers "github.com/sap/clustersecret-operator/pkg/client/listers/core.cs.sap.com/v1alpha1"
 )
 
 type Handler struct {
+	coreinformerFactory coreinformers.SharedInformerFactory     // core informer factory
+	clusterSecretLister corev1alpha1listers.ClusterSecretLister // clustersecret lister; used to check for conflicts with existing clustersecrets
 }
 
-func NewHandler() *Handler {
-	return &Handler{}
+func NewHandler(coreclient coreclients.Interface) *Handler {
+	coreinformerFactory := coreinformers.NewSharedInformerFactory(coreclient, 0)
+	csInformer := coreinformerFactory.Core().V1alpha1().ClusterSecrets()
+	// attention: important to create informer and lister before starting the factory !!!
+	csInformer.Informer()
+	clusterSecretLister := csInformer.Lister()
+	return &Handler{
+		coreinformerFactory: coreinformerFactory,
+		clusterSecretLister: clusterSecretLister,
+	}
+}
+
+// start the informers of the handler and wait until their caches are synced;
+// must be called before the handler starts serving requests
+func (h *Handler) Start(ctx context.Context) error {
+	h.coreinformerFactory.Start(ctx.Done())
+	for _, ok := range h.coreinformerFactory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("error waiting for informer caches to sync")
+		}
+	}
+	return nil
 }
 
 func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
@@ -30,63 +66,95 @@ func (h *Handler) Mutate(w http.ResponseWriter, r *http.Request) {
 	handle(w, r, h.mutate)
 }
 
-type admitFunc func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+type admitFunc func(context.Context, *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
 
 func handle(w http.ResponseWriter, r *http.Request, admit admitFunc) {
+	// continue the trace of the caller (if any), e.g. of the api server
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := tracing.Tracer().Start(ctx, "admission "+r.URL.Path, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	r = r.WithContext(ctx)
+
 	switch r.Method {
 	case http.MethodGet, http.MethodHead:
 		// GET, HEAD should be supported by all web servers, so we return 400 here instead of 405
-		httpError(w, http.StatusBadRequest, fmt.Errorf("admission error: bad method, expect POST"))
+		httpError(w, r, http.StatusBadRequest, fmt.Errorf("admission error: bad method, expect POST"))
 		return
 	case http.MethodPost:
 		// ok
 	default:
 		// other methods are rejected with 405
-		httpError(w, http.StatusMethodNotAllowed, fmt.Errorf("admission error: bad method, expect POST"))
+		httpError(w, r, http.StatusMethodNotAllowed, fmt.Errorf("admission error: bad method, expect POST"))
 		return
 	}
 
 	if r.Body == nil {
-		httpError(w, http.StatusBadRequest, fmt.Errorf("admission error: empty reqeuest"))
+		httpError(w, r, http.StatusBadRequest, fmt.Errorf("admission error: empty reqeuest"))
 		return
 	}
 
 	reqBody, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		httpError(w, http.StatusInternalServerError, fmt.Errorf("admission error: %s", err))
+		httpError(w, r, http.StatusInternalServerError, fmt.Errorf("admission error: %s", err))
 		return
 	}
 
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "application/json" {
-		httpError(w, http.StatusUnsupportedMediaType, fmt.Errorf("admission error: got content-type '%s', expect 'application/json'", contentType))
+		httpError(w, r, http.StatusUnsupportedMediaType, fmt.Errorf("admission error: got content-type '%s', expect 'application/json'", contentType))
 		return
 	}
 
 	requestAdmissionReview := admissionv1.AdmissionReview{}
 	deserializer := codecs.UniversalDeserializer()
 	if _, _, err := deserializer.Decode(reqBody, nil, &requestAdmissionReview); err != nil {
-		httpError(w, http.StatusBadRequest, fmt.Errorf("admission error: %s", err))
+		httpError(w, r, http.StatusBadRequest, fmt.Errorf("admission error: %s", err))
 		return
 	}
 	if requestAdmissionReview.APIVersion != admissionv1.GroupName+"/v1" || requestAdmissionReview.Kind != "AdmissionReview" {
-		httpError(w, http.StatusBadRequest, fmt.Errorf("admission error: got '%s' '%s', expect '%s' '%s'", requestAdmissionReview.APIVersion, requestAdmissionReview.Kind, admissionv1.GroupName+"/v1", "AdmissionReview"))
+		httpError(w, r, http.StatusBadRequest, fmt.Errorf("admission error: got '%s' '%s', expect '%s' '%s'", requestAdmissionReview.APIVersion, requestAdmissionReview.Kind, admissionv1.GroupName+"/v1", "AdmissionReview"))
 		return
 	}
 	if requestAdmissionReview.Request == nil || requestAdmissionReview.Request.UID == "" {
-		httpError(w, http.StatusBadRequest, fmt.Errorf("admission error: empty or incomplete review request"))
+		httpError(w, r, http.StatusBadRequest, fmt.Errorf("admission error: empty or incomplete review request"))
 		return
 	}
 
+	request := requestAdmissionReview.Request
+	logger := klog.FromContext(r.Context()).WithValues("uid", request.UID, "operation", request.Operation, "clustersecret", request.Name).WithValues(tracing.LogValues(ctx)...)
+	logger.V(2).Info("handling admission request", "path", r.URL.Path)
+	span.SetAttributes(
+		tracing.AttributeKeyClusterSecret.String(request.Name),
+		attribute.String("admission.operation", string(request.Operation)),
+		attribute.String("admission.uid", string(request.UID)),
+	)
+
 	responseAdmissionReview := admissionv1.AdmissionReview{}
-	responseAdmissionReview.Response = admit(requestAdmissionReview.Request)
+	responseAdmissionReview.Response = admit(klog.NewContext(ctx, logger), request)
+	span.SetAttributes(attribute.Bool("admission.allowed", responseAdmissionReview.Response.Allowed))
+	if !responseAdmissionReview.Response.Allowed {
+		var message string
+		if responseAdmissionReview.Response.Result != nil {
+			message = responseAdmissionReview.Response.Result.Message
+		}
+		logger.Error(errors.New(message), "admission request denied")
+		span.SetStatus(codes.Error, message)
+	}
 	responseAdmissionReview.Kind = requestAdmissionReview.Kind
 	responseAdmissionReview.APIVersion = requestAdmissionReview.APIVersion
-	responseAdmissionReview.Response.UID = requestAdmissionReview.Request.UID
+	responseAdmissionReview.Response.UID = request.UID
+	// if the request is traced, expose the trace in the audit log of the api server (as annotation <webhook name>/traceparent of the audit event),
+	// such that the change can be correlated with its trace; note that the trace is deliberately not recorded on the clustersecret itself
+	if span.IsRecording() {
+		if responseAdmissionReview.Response.AuditAnnotations == nil {
+			responseAdmissionReview.Response.AuditAnnotations = make(map[string]string)
+		}
+		responseAdmissionReview.Response.AuditAnnotations["traceparent"] = tracing.TraceParent(ctx)
+	}
 
 	respBody, err := json.Marshal(responseAdmissionReview)
 	if err != nil {
-		httpError(w, http.StatusInternalServerError, fmt.Errorf("admission error: %s", err))
+		httpError(w, r, http.StatusInternalServerError, fmt.Errorf("admission error: %s", err))
 		return
 	}
 	if _, err := w.Write(respBody); err != nil {
@@ -94,7 +162,8 @@ func handle(w http.ResponseWriter, r *http.Request, admit admitFunc) {
 	}
 }
 
-func httpError(w http.ResponseWriter, code int, err error) {
-	klog.Error(err)
+func httpError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	klog.FromContext(r.Context()).Error(err, "error handling admission request", "path", r.URL.Path)
+	tracing.RecordError(trace.SpanFromContext(r.Context()), err)
 	http.Error(w, err.Error(), code)
 }
@@ -0,0 +1,104 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/sap/clustersecret-operator/internal/tracing"
+	encodingutils "github.com/sap/clustersecret-operator/internal/utils/encoding"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+	corefake "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned/fake"
+)
+
+// test: tracing of admission requests
+func TestHandleTracing(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	defer tracing.SetTracerProvider(noop.NewTracerProvider())
+
+	request := newAdmissionRequest(admissionv1.Create, newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{}))
+	request.UID = "0e3b2d5c-4c4c-4f5e-9d4b-6a0a1f1a1a1a"
+	request.Name = "my-secret"
+	review := admissionv1.AdmissionReview{Request: request}
+	review.APIVersion = admissionv1.GroupName + "/v1"
+	review.Kind = "AdmissionReview"
+
+	r := httptest.NewRequest(http.MethodPost, "/mutation", bytes.NewReader(encodingutils.ToJson(review)))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	NewHandler(corefake.NewSimpleClientset()).Mutate(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected http status; expected: %d, actual: %d", http.StatusOK, w.Code)
+	}
+	var responseReview admissionv1.AdmissionReview
+	if err := json.Unmarshal(w.Body.Bytes(), &responseReview); err != nil {
+		t.Fatal(err)
+	}
+	if responseReview.Response == nil || !responseReview.Response.Allowed {
+		t.Fatalf("admission request not allowed")
+	}
+
+	spans := spanRecorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "admission /mutation" {
+		t.Fatalf("expected span not recorded")
+	}
+	span := spans[0]
+	if traceID := span.SpanContext().TraceID().String(); traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("admission span does not continue the caller's trace; expected trace id: 4bf92f3577b34da6a3ce929d0e0e4736, actual: %s", traceID)
+	}
+	found := false
+	for _, attribute := range span.Attributes() {
+		if attribute.Key == tracing.AttributeKeyClusterSecret && attribute.Value.AsString() == "my-secret" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("admission span misses attribute %s", tracing.AttributeKeyClusterSecret)
+	}
+
+	// the trace must be exposed through the audit annotations, but not be recorded on the clustersecret
+	traceParent := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if value := responseReview.Response.AuditAnnotations["traceparent"]; value != traceParent {
+		t.Errorf("unexpected audit annotation traceparent; expected: %s, actual: %s", traceParent, value)
+	}
+	if strings.Contains(string(responseReview.Response.Patch), "traceparent") {
+		t.Errorf("patch must not record the trace parent: %s", string(responseReview.Response.Patch))
+	}
+
+	// with tracing disabled, the trace of the caller must not be propagated
+	tracing.SetTracerProvider(noop.NewTracerProvider())
+	r = httptest.NewRequest(http.MethodPost, "/mutation", bytes.NewReader(encodingutils.ToJson(review)))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w = httptest.NewRecorder()
+	NewHandler(corefake.NewSimpleClientset()).Mutate(w, r)
+
+	responseReview = admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(w.Body.Bytes(), &responseReview); err != nil {
+		t.Fatal(err)
+	}
+	if responseReview.Response == nil || !responseReview.Response.Allowed {
+		t.Fatalf("admission request not allowed")
+	}
+	if len(responseReview.Response.AuditAnnotations) > 0 {
+		t.Errorf("unexpected audit annotations with tracing disabled: %v", responseReview.Response.AuditAnnotations)
+	}
+}
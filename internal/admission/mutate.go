@@ -6,19 +6,20 @@ SPDX-License-Identifier: Apache-2.0
 package admission
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/sap/clustersecret-operator/internal/controller"
+	"github.com/sap/clustersecret-operator/internal/common"
 	encodingutils "github.com/sap/clustersecret-operator/internal/utils/encoding"
 
 	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 )
 
-func (h *Handler) mutate(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+func (h *Handler) mutate(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
 	// check that we are called with the right resources only
 	if request.Resource != metav1.GroupVersionResource(corev1alpha1.ClusterSecretGroupVersionResource) {
 		return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: this webhook must not be called for resources of type '%s'", &request.Resource))
@@ -43,13 +44,13 @@ func (h *Handler) mutate(request *admissionv1.AdmissionRequest) *admissionv1.Adm
 	if request.Operation == admissionv1.Create {
 		exists := false
 		for _, finalizer := range clusterSecret.Finalizers {
-			if finalizer == controller.ControllerName {
+			if finalizer == common.ControllerName {
 				exists = true
 				break
 			}
 		}
 		if !exists {
-			clusterSecret.Finalizers = append(clusterSecret.Finalizers, controller.ControllerName)
+			clusterSecret.Finalizers = append(clusterSecret.Finalizers, common.ControllerName)
 			patches = append(patches, map[string]interface{}{"op": "add", "path": "/metadata/finalizers", "value": clusterSecret.Finalizers})
 		}
 	}
@@ -10,11 +10,9 @@ import (
 
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog/v2"
 )
 
 func admissionError(code int, err error) *admissionv1.AdmissionResponse {
-	klog.Error(err)
 	return &admissionv1.AdmissionResponse{
 		Allowed: false,
 		Result: &metav1.Status{
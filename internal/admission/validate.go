@@ -6,20 +6,30 @@ SPDX-License-Identifier: Apache-2.0
 package admission
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/go-multierror"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/validation"
 
+	"github.com/sap/clustersecret-operator/internal/common"
+	"github.com/sap/clustersecret-operator/internal/templating"
+
 	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 )
 
-func (h *Handler) validate(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+func (h *Handler) validate(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
 	// check that we are called with the right resources only
 	if request.Resource != metav1.GroupVersionResource(corev1alpha1.ClusterSecretGroupVersionResource) {
 		return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: this webhook must not be called for resources of type '%s'", &request.Resource))
@@ -36,6 +46,13 @@ func (h *Handler) validate(request *admissionv1.AdmissionRequest) *admissionv1.A
 	if _, _, err := deserializer.Decode(request.Object.Raw, nil, &clusterSecret); err != nil {
 		return admissionError(http.StatusInternalServerError, fmt.Errorf("admission error: %s", err))
 	}
+	var oldClusterSecret *corev1alpha1.ClusterSecret
+	if request.Operation == admissionv1.Update {
+		oldClusterSecret = &corev1alpha1.ClusterSecret{}
+		if _, _, err := deserializer.Decode(request.OldObject.Raw, nil, oldClusterSecret); err != nil {
+			return admissionError(http.StatusInternalServerError, fmt.Errorf("admission error: %s", err))
+		}
+	}
 
 	// perform validations ...
 
@@ -44,6 +61,27 @@ func (h *Handler) validate(request *admissionv1.AdmissionRequest) *admissionv1.A
 		return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: unexpected field stringData"))
 	}
 
+	// ... check that exactly one of data and sourceRef is set; the check is skipped for updates not touching the spec, and for clustersecrets
+	// which are being deleted (such that clustersecrets created before this rule was introduced can still be relabeled, or be finalized)
+	if clusterSecret.DeletionTimestamp == nil && (oldClusterSecret == nil || !equality.Semantic.DeepEqual(oldClusterSecret.Spec, clusterSecret.Spec)) {
+		if clusterSecret.Spec.SourceRef == nil && len(clusterSecret.Spec.Template.Data) == 0 {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: exactly one of template.data and sourceRef must be set (found none)"))
+		}
+		if clusterSecret.Spec.SourceRef != nil && len(clusterSecret.Spec.Template.Data) > 0 {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: exactly one of template.data and sourceRef must be set (found both)"))
+		}
+	}
+
+	// ... check source reference
+	if sourceRef := clusterSecret.Spec.SourceRef; sourceRef != nil {
+		if clusterSecret.Spec.Template.Templated {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: templating is not supported in combination with sourceRef"))
+		}
+		if err := validateSourceRef(sourceRef); err != nil {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: %s", err))
+		}
+	}
+
 	// ... check namespace selector
 	if clusterSecret.Spec.NamespaceSelector != nil {
 		if err := validateLabelSelector(clusterSecret.Spec.NamespaceSelector); err != nil {
@@ -51,6 +89,43 @@ func (h *Handler) validate(request *admissionv1.AdmissionRequest) *admissionv1.A
 		}
 	}
 
+	// ... check secret name
+	if clusterSecret.Spec.Template.Name != "" {
+		if err := validateSecretName(clusterSecret.Spec.Template.Name); err != nil {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: %s", err))
+		}
+	}
+
+	// ... check secret labels and annotations
+	if metadata := clusterSecret.Spec.Template.Metadata; metadata != nil {
+		for key, value := range metadata.Labels {
+			if strings.HasPrefix(key, common.ReservedKeyPrefix) {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: label key %s is reserved (must not start with %s)", key, common.ReservedKeyPrefix))
+			}
+			if err := validateLabelKey(key); err != nil {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid label key: %s (%s)", key, err))
+			}
+			if err := validateLabelValue(value); err != nil {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid label value: %s: %s (%s)", key, value, err))
+			}
+		}
+		for key := range metadata.Annotations {
+			if strings.HasPrefix(key, common.ReservedKeyPrefix) {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: annotation key %s is reserved (must not start with %s)", key, common.ReservedKeyPrefix))
+			}
+			if err := validateLabelKey(key); err != nil {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid annotation key: %s (%s)", key, err))
+			}
+		}
+	}
+
+	// ... check rollback annotation
+	if value, ok := clusterSecret.Annotations[common.AnnotationKeyRollbackTo]; ok {
+		if revision, err := strconv.ParseInt(value, 10, 64); err != nil || revision <= 0 {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid value of annotation %s: %s (must be a positive integer)", common.AnnotationKeyRollbackTo, value))
+		}
+	}
+
 	// ... check data keys
 	for key := range clusterSecret.Spec.Template.Data {
 		if err := validateSecretKey(key); err != nil {
@@ -58,11 +133,94 @@ func (h *Handler) validate(request *admissionv1.AdmissionRequest) *admissionv1.A
 		}
 	}
 
+	// ... check namespace names
+	if namespaces := clusterSecret.Spec.Namespaces; namespaces != nil {
+		for _, pattern := range append(append([]string{}, namespaces.Include...), namespaces.Exclude...) {
+			if err := validateNamespacePattern(pattern); err != nil {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: %s", err))
+			}
+		}
+	}
+
+	// ... check deletion policy
+	switch clusterSecret.Spec.DeletionPolicy {
+	case "", corev1alpha1.DeletionPolicyDelete, corev1alpha1.DeletionPolicyOrphan:
+	default:
+		return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid deletion policy %s (must be one of %s, %s)", clusterSecret.Spec.DeletionPolicy, corev1alpha1.DeletionPolicyDelete, corev1alpha1.DeletionPolicyOrphan))
+	}
+
+	// ... check conflict policy
+	switch clusterSecret.Spec.ConflictPolicy {
+	case "", corev1alpha1.ConflictPolicyFail, corev1alpha1.ConflictPolicySkip, corev1alpha1.ConflictPolicyAdopt:
+	default:
+		return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid conflict policy %s (must be one of %s, %s, %s)", clusterSecret.Spec.ConflictPolicy, corev1alpha1.ConflictPolicyFail, corev1alpha1.ConflictPolicySkip, corev1alpha1.ConflictPolicyAdopt))
+	}
+
+	// ... check overrides
+	for i, override := range clusterSecret.Spec.Overrides {
+		if override.NamespaceSelector == nil {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: missing namespace selector in override %d", i))
+		}
+		if err := validateLabelSelector(override.NamespaceSelector); err != nil {
+			return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid override %d: %s", i, err))
+		}
+		for key := range override.Data {
+			if err := validateSecretKey(key); err != nil {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid override %d: %s", i, err))
+			}
+		}
+	}
+
+	// ... check that data values are valid templates (if templating is enabled)
+	if clusterSecret.Spec.Template.Templated {
+		for key, value := range clusterSecret.Spec.Template.Data {
+			if err := templating.Parse(key, string(value)); err != nil {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid template for secret key %s (%s)", key, err))
+			}
+		}
+		for i, override := range clusterSecret.Spec.Overrides {
+			for key, value := range override.Data {
+				if err := templating.Parse(key, string(value)); err != nil {
+					return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: invalid template for secret key %s in override %d (%s)", key, i, err))
+				}
+			}
+		}
+	}
+
+	// ... check that no other clustersecret could manage a secret with the same name in the same namespace;
+	// the check is only done on creation, or if the secret name or the namespace selection is changed, and it is skipped for
+	// clustersecrets which are being deleted (such that, for example, removing finalizers is not blocked by existing conflicts)
+	if clusterSecret.DeletionTimestamp == nil && (oldClusterSecret == nil || secretPlacementChanged(oldClusterSecret, &clusterSecret)) {
+		otherClusterSecrets, err := h.clusterSecretLister.List(labels.Everything())
+		if err != nil {
+			return admissionError(http.StatusInternalServerError, fmt.Errorf("admission error: %s", err))
+		}
+		for _, otherClusterSecret := range otherClusterSecrets {
+			if otherClusterSecret.Name == clusterSecret.Name {
+				continue
+			}
+			if common.GetSecretName(otherClusterSecret) != common.GetSecretName(&clusterSecret) {
+				continue
+			}
+			if namespaceSelectorsMayOverlap(otherClusterSecret.Spec.NamespaceSelector, clusterSecret.Spec.NamespaceSelector) &&
+				namespaceNamesMayOverlap(otherClusterSecret.Spec.Namespaces, clusterSecret.Spec.Namespaces) {
+				return admissionError(http.StatusBadRequest, fmt.Errorf("admission error: clustersecret %s manages secrets with the same name (%s) in potentially the same namespaces", otherClusterSecret.Name, common.GetSecretName(&clusterSecret)))
+			}
+		}
+	}
+
 	// assemble response and return
 	response := admissionv1.AdmissionResponse{Allowed: true}
 	return &response
 }
 
+// check if the name of the managed secrets, or the selection of the namespaces they are created in, differs between the given clustersecrets
+func secretPlacementChanged(oldClusterSecret *corev1alpha1.ClusterSecret, clusterSecret *corev1alpha1.ClusterSecret) bool {
+	return oldClusterSecret.Spec.Template.Name != clusterSecret.Spec.Template.Name ||
+		!equality.Semantic.DeepEqual(oldClusterSecret.Spec.NamespaceSelector, clusterSecret.Spec.NamespaceSelector) ||
+		!equality.Semantic.DeepEqual(oldClusterSecret.Spec.Namespaces, clusterSecret.Spec.Namespaces)
+}
+
 func validateLabelSelector(selector *metav1.LabelSelector) error {
 	for key, value := range selector.MatchLabels {
 		if err := validateLabelKey(key); err != nil {
@@ -113,9 +271,158 @@ func validateLabelValue(value string) error {
 	return merr.ErrorOrNil()
 }
 
+func validateSecretName(name string) error {
+	var merr *multierror.Error
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		merr = multierror.Append(merr, errors.New(msg))
+	}
+	if err := merr.ErrorOrNil(); err != nil {
+		return fmt.Errorf("invalid secret name: %s (%s)", name, err)
+	}
+	return nil
+}
+
+func validateNamespacePattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid namespace pattern: %s (%s)", pattern, err)
+	}
+	if isNamespacePattern(pattern) {
+		if !regexp.MustCompile(`^[a-z0-9\-*?\[\]^]+$`).MatchString(pattern) {
+			return fmt.Errorf("invalid namespace pattern: %s (must only contain lower case alphanumeric characters, '-', and glob characters '*', '?', '[', ']', '^')", pattern)
+		}
+		return nil
+	}
+	var merr *multierror.Error
+	for _, msg := range validation.IsDNS1123Label(pattern) {
+		merr = multierror.Append(merr, errors.New(msg))
+	}
+	if err := merr.ErrorOrNil(); err != nil {
+		return fmt.Errorf("invalid namespace name: %s (%s)", pattern, err)
+	}
+	return nil
+}
+
+func validateSourceRef(sourceRef *corev1alpha1.SecretReference) error {
+	var merr *multierror.Error
+	for _, msg := range validation.IsDNS1123Label(sourceRef.Namespace) {
+		merr = multierror.Append(merr, errors.New(msg))
+	}
+	if err := merr.ErrorOrNil(); err != nil {
+		return fmt.Errorf("invalid source namespace: %s (%s)", sourceRef.Namespace, err)
+	}
+	if err := validateSecretName(sourceRef.Name); err != nil {
+		return fmt.Errorf("invalid source secret: %s", err)
+	}
+	for _, key := range sourceRef.Keys {
+		if err := validateSecretKey(key); err != nil {
+			return fmt.Errorf("invalid source secret key: %s", err)
+		}
+	}
+	return nil
+}
+
 func validateSecretKey(key string) error {
 	if !regexp.MustCompile(`^[A-Za-z0-9_\-.]*$`).MatchString(key) {
 		return fmt.Errorf("invalid secret key: %s", key)
 	}
 	return nil
 }
+
+// check if there could exist a set of labels matched by both selectors; the check is conservative,
+// i.e. it may return true for selectors which are actually disjoint, but never returns false for overlapping selectors
+// note: selectors are expected to be valid (in particular, to be convertible by metav1.LabelSelectorAsSelector)
+func namespaceSelectorsMayOverlap(x *metav1.LabelSelector, y *metav1.LabelSelector) bool {
+	xSelector, err := metav1.LabelSelectorAsSelector(x)
+	if err != nil {
+		return true
+	}
+	ySelector, err := metav1.LabelSelectorAsSelector(y)
+	if err != nil {
+		return true
+	}
+	xRequirements, _ := xSelector.Requirements()
+	yRequirements, _ := ySelector.Requirements()
+	for _, xRequirement := range xRequirements {
+		for _, yRequirement := range yRequirements {
+			if xRequirement.Key() == yRequirement.Key() && requirementsExclude(xRequirement, yRequirement) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// check if there could exist a namespace name selected by both include/exclude lists; as above, the check is conservative,
+// i.e. it may return true for lists which are actually disjoint (for example, if both include lists contain overlapping glob patterns)
+func namespaceNamesMayOverlap(x *corev1alpha1.NamespaceNames, y *corev1alpha1.NamespaceNames) bool {
+	xInclude, xExclude := namespaceNameLists(x)
+	yInclude, yExclude := namespaceNameLists(y)
+	exclude := append(append([]string{}, xExclude...), yExclude...)
+	for _, xPattern := range xInclude {
+		for _, yPattern := range yInclude {
+			switch {
+			case !isNamespacePattern(xPattern):
+				if common.NamespaceNameMatchesAny(xPattern, []string{yPattern}) && !common.NamespaceNameMatchesAny(xPattern, exclude) {
+					return true
+				}
+			case !isNamespacePattern(yPattern):
+				if common.NamespaceNameMatchesAny(yPattern, []string{xPattern}) && !common.NamespaceNameMatchesAny(yPattern, exclude) {
+					return true
+				}
+			default:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func namespaceNameLists(namespaces *corev1alpha1.NamespaceNames) ([]string, []string) {
+	if namespaces == nil {
+		return []string{"*"}, nil
+	}
+	include := namespaces.Include
+	if len(include) == 0 {
+		include = []string{"*"}
+	}
+	return include, namespaces.Exclude
+}
+
+// check if the specified string contains glob characters
+func isNamespacePattern(s string) bool {
+	return strings.ContainsAny(s, "*?[]^\\")
+}
+
+// check if the two requirements (on the same key) cannot be fulfilled at the same time
+func requirementsExclude(x labels.Requirement, y labels.Requirement) bool {
+	switch {
+	case isPositiveRequirement(x) && isPositiveRequirement(y):
+		if x.Operator() == selection.Exists || y.Operator() == selection.Exists {
+			return false
+		}
+		return !x.Values().HasAny(y.Values().UnsortedList()...)
+	case isPositiveRequirement(x) && y.Operator() == selection.DoesNotExist, x.Operator() == selection.DoesNotExist && isPositiveRequirement(y):
+		return true
+	case isValueRequirement(x) && y.Operator() == selection.NotIn:
+		return y.Values().IsSuperset(x.Values())
+	case x.Operator() == selection.NotIn && isValueRequirement(y):
+		return x.Values().IsSuperset(y.Values())
+	default:
+		return false
+	}
+}
+
+// requirement can only be fulfilled if the key exists
+func isPositiveRequirement(r labels.Requirement) bool {
+	return r.Operator() == selection.Exists || isValueRequirement(r)
+}
+
+// requirement can only be fulfilled if the key has one of the requirement's values
+func isValueRequirement(r labels.Requirement) bool {
+	switch r.Operator() {
+	case selection.In, selection.Equals, selection.DoubleEquals:
+		return true
+	default:
+		return false
+	}
+}
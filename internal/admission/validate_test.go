@@ -0,0 +1,674 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/sap/clustersecret-operator/internal/common"
+	encodingutils "github.com/sap/clustersecret-operator/internal/utils/encoding"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+	corefake "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned/fake"
+)
+
+func newAdmissionRequest(operation admissionv1.Operation, clusterSecret *corev1alpha1.ClusterSecret) *admissionv1.AdmissionRequest {
+	clusterSecret.APIVersion = corev1alpha1.GroupVersion.String()
+	clusterSecret.Kind = corev1alpha1.ClusterSecretKind
+	return &admissionv1.AdmissionRequest{
+		Resource:  metav1.GroupVersionResource(corev1alpha1.ClusterSecretGroupVersionResource),
+		Operation: operation,
+		Object:    runtime.RawExtension{Raw: encodingutils.ToJson(clusterSecret)},
+	}
+}
+
+func newUpdateAdmissionRequest(oldClusterSecret *corev1alpha1.ClusterSecret, clusterSecret *corev1alpha1.ClusterSecret) *admissionv1.AdmissionRequest {
+	request := newAdmissionRequest(admissionv1.Update, clusterSecret)
+	oldClusterSecret.APIVersion = corev1alpha1.GroupVersion.String()
+	oldClusterSecret.Kind = corev1alpha1.ClusterSecretKind
+	request.OldObject = runtime.RawExtension{Raw: encodingutils.ToJson(oldClusterSecret)}
+	return request
+}
+
+func newHandler(t *testing.T, objects ...runtime.Object) *Handler {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	h := NewHandler(corefake.NewSimpleClientset(objects...))
+	if err := h.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func newClusterSecret(name string, template corev1alpha1.SecretTemplateSpec) *corev1alpha1.ClusterSecret {
+	if template.Type == "" {
+		template.Type = corev1.SecretTypeOpaque
+	}
+	if template.Data == nil {
+		template.Data = map[string][]byte{"key": []byte("value")}
+	}
+	return &corev1alpha1.ClusterSecret{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1alpha1.ClusterSecretSpec{
+			Template: template,
+		},
+	}
+}
+
+// test: validation of secret templates
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template corev1alpha1.SecretTemplateSpec
+		allowed  bool
+	}{
+		{
+			name:     "plain data",
+			template: corev1alpha1.SecretTemplateSpec{Data: map[string][]byte{"key": []byte("{{ invalid")}},
+			allowed:  true,
+		},
+		{
+			name:     "valid template",
+			template: corev1alpha1.SecretTemplateSpec{Templated: true, Data: map[string][]byte{"key": []byte(`{{ .Namespace.Name }}.{{ label "x" | default "y" | upper }}`)}},
+			allowed:  true,
+		},
+		{
+			name:     "invalid template",
+			template: corev1alpha1.SecretTemplateSpec{Templated: true, Data: map[string][]byte{"key": []byte("{{ invalid")}},
+			allowed:  false,
+		},
+		{
+			name:     "unknown template function",
+			template: corev1alpha1.SecretTemplateSpec{Templated: true, Data: map[string][]byte{"key": []byte("{{ env \"HOME\" }}")}},
+			allowed:  false,
+		},
+		{
+			name: "custom labels and annotations",
+			template: corev1alpha1.SecretTemplateSpec{Metadata: &corev1alpha1.SecretTemplateMetadata{
+				Labels:      map[string]string{"app.kubernetes.io/part-of": "my-app"},
+				Annotations: map[string]string{"my-annotation": "any value"},
+			}},
+			allowed: true,
+		},
+		{
+			name: "reserved label",
+			template: corev1alpha1.SecretTemplateSpec{Metadata: &corev1alpha1.SecretTemplateMetadata{
+				Labels: map[string]string{"clustersecrets.core.cs.sap.com/name": "other"},
+			}},
+			allowed: false,
+		},
+		{
+			name: "reserved annotation",
+			template: corev1alpha1.SecretTemplateSpec{Metadata: &corev1alpha1.SecretTemplateMetadata{
+				Annotations: map[string]string{"clustersecrets.core.cs.sap.com/generation": "1"},
+			}},
+			allowed: false,
+		},
+		{
+			name: "invalid label value",
+			template: corev1alpha1.SecretTemplateSpec{Metadata: &corev1alpha1.SecretTemplateMetadata{
+				Labels: map[string]string{"mylabel": "invalid value"},
+			}},
+			allowed: false,
+		},
+	}
+
+	h := newHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := h.validate(context.TODO(), newAdmissionRequest(admissionv1.Create, newClusterSecret("my-secret", tt.template)))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of secret names (conflicts with other clustersecrets)
+func TestValidateSecretName(t *testing.T) {
+	existingClusterSecret := newClusterSecret("existing", corev1alpha1.SecretTemplateSpec{Name: "registry-credentials"})
+	existingClusterSecret.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"stage": "dev"},
+	}
+
+	tests := []struct {
+		name              string
+		clusterSecretName string
+		secretName        string
+		namespaceSelector *metav1.LabelSelector
+		allowed           bool
+	}{
+		{
+			name:              "invalid name",
+			clusterSecretName: "my-secret",
+			secretName:        "Invalid_Name",
+			allowed:           false,
+		},
+		{
+			name:              "different name",
+			clusterSecretName: "my-secret",
+			secretName:        "other-credentials",
+			allowed:           true,
+		},
+		{
+			name:              "same name, all namespaces",
+			clusterSecretName: "my-secret",
+			secretName:        "registry-credentials",
+			allowed:           false,
+		},
+		{
+			name:              "same name as clustersecret name",
+			clusterSecretName: "registry-credentials",
+			allowed:           false,
+		},
+		{
+			name:              "same name, overlapping selector",
+			clusterSecretName: "my-secret",
+			secretName:        "registry-credentials",
+			namespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "stage", Operator: metav1.LabelSelectorOpIn, Values: []string{"dev", "test"}}},
+			},
+			allowed: false,
+		},
+		{
+			name:              "same name, disjoint selector (values)",
+			clusterSecretName: "my-secret",
+			secretName:        "registry-credentials",
+			namespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"stage": "prod"},
+			},
+			allowed: true,
+		},
+		{
+			name:              "same name, disjoint selector (not in)",
+			clusterSecretName: "my-secret",
+			secretName:        "registry-credentials",
+			namespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "stage", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"dev"}}},
+			},
+			allowed: true,
+		},
+		{
+			name:              "same name, disjoint selector (does not exist)",
+			clusterSecretName: "my-secret",
+			secretName:        "registry-credentials",
+			namespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "stage", Operator: metav1.LabelSelectorOpDoesNotExist}},
+			},
+			allowed: true,
+		},
+		{
+			name:              "same name, selector on other key",
+			clusterSecretName: "my-secret",
+			secretName:        "registry-credentials",
+			namespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"team": "a"},
+			},
+			allowed: false,
+		},
+		{
+			name:              "update of existing clustersecret",
+			clusterSecretName: "existing",
+			secretName:        "registry-credentials",
+			allowed:           true,
+		},
+	}
+
+	h := newHandler(t, existingClusterSecret)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterSecret := newClusterSecret(tt.clusterSecretName, corev1alpha1.SecretTemplateSpec{Name: tt.secretName})
+			clusterSecret.Spec.NamespaceSelector = tt.namespaceSelector
+			response := h.validate(context.TODO(), newAdmissionRequest(admissionv1.Create, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of secret names on updates (conflicts are only checked if the secret name or the namespace selection changes)
+func TestValidateSecretNameUpdate(t *testing.T) {
+	existingClusterSecret := newClusterSecret("existing", corev1alpha1.SecretTemplateSpec{Name: "registry-credentials"})
+	existingClusterSecret.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"stage": "dev"},
+	}
+
+	tests := []struct {
+		name       string
+		secretName string
+		modify     func(clusterSecret *corev1alpha1.ClusterSecret)
+		allowed    bool
+	}{
+		{
+			name:       "data change of conflicting clustersecret",
+			secretName: "registry-credentials",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.Spec.Template.Data = map[string][]byte{"key": []byte("other value")}
+			},
+			allowed: true,
+		},
+		{
+			name:       "secret name change to conflicting name",
+			secretName: "other-credentials",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.Spec.Template.Name = "registry-credentials"
+			},
+			allowed: false,
+		},
+		{
+			name:       "selector change to overlapping selector",
+			secretName: "registry-credentials",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+			},
+			allowed: false,
+		},
+		{
+			name:       "namespaces change to overlapping namespaces",
+			secretName: "registry-credentials",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.Spec.Namespaces = &corev1alpha1.NamespaceNames{Include: []string{"team-*"}}
+			},
+			allowed: false,
+		},
+		{
+			name:       "selector change of clustersecret being deleted",
+			secretName: "registry-credentials",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				clusterSecret.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+			},
+			allowed: true,
+		},
+	}
+
+	h := newHandler(t, existingClusterSecret)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldClusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{Name: tt.secretName})
+			clusterSecret := oldClusterSecret.DeepCopy()
+			tt.modify(clusterSecret)
+			response := h.validate(context.TODO(), newUpdateAdmissionRequest(oldClusterSecret, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of source references
+func TestValidateSourceRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string][]byte
+		templated bool
+		sourceRef *corev1alpha1.SecretReference
+		allowed   bool
+	}{
+		{
+			name:    "neither data nor source",
+			data:    map[string][]byte{},
+			allowed: false,
+		},
+		{
+			name:      "source",
+			data:      map[string][]byte{},
+			sourceRef: &corev1alpha1.SecretReference{Namespace: "platform", Name: "credentials", Keys: []string{"username", "password"}},
+			allowed:   true,
+		},
+		{
+			name:      "data and source",
+			sourceRef: &corev1alpha1.SecretReference{Namespace: "platform", Name: "credentials"},
+			allowed:   false,
+		},
+		{
+			name:      "source with templating",
+			data:      map[string][]byte{},
+			templated: true,
+			sourceRef: &corev1alpha1.SecretReference{Namespace: "platform", Name: "credentials"},
+			allowed:   false,
+		},
+		{
+			name:      "invalid source namespace",
+			data:      map[string][]byte{},
+			sourceRef: &corev1alpha1.SecretReference{Namespace: "Platform", Name: "credentials"},
+			allowed:   false,
+		},
+		{
+			name:      "invalid source key",
+			data:      map[string][]byte{},
+			sourceRef: &corev1alpha1.SecretReference{Namespace: "platform", Name: "credentials", Keys: []string{"user/name"}},
+			allowed:   false,
+		},
+	}
+
+	h := newHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{Templated: tt.templated, Data: tt.data})
+			clusterSecret.Spec.SourceRef = tt.sourceRef
+			response := h.validate(context.TODO(), newAdmissionRequest(admissionv1.Create, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of data and source references on updates (inconsistent specs are accepted if the spec is not changed, or on deletion)
+func TestValidateSourceRefUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(clusterSecret *corev1alpha1.ClusterSecret)
+		allowed bool
+	}{
+		{
+			name: "metadata change",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.Labels = map[string]string{"team": "a"}
+			},
+			allowed: true,
+		},
+		{
+			name: "deletion",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				clusterSecret.Finalizers = nil
+			},
+			allowed: true,
+		},
+		{
+			name: "spec change",
+			modify: func(clusterSecret *corev1alpha1.ClusterSecret) {
+				clusterSecret.Spec.Template.Data["other"] = []byte("value")
+			},
+			allowed: false,
+		},
+	}
+
+	h := newHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldClusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{})
+			oldClusterSecret.Spec.SourceRef = &corev1alpha1.SecretReference{Namespace: "platform", Name: "credentials"}
+			oldClusterSecret.Finalizers = []string{"test"}
+			clusterSecret := oldClusterSecret.DeepCopy()
+			tt.modify(clusterSecret)
+			response := h.validate(context.TODO(), newUpdateAdmissionRequest(oldClusterSecret, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of data overrides
+func TestValidateOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		templated bool
+		overrides []corev1alpha1.DataOverride
+		allowed   bool
+	}{
+		{
+			name: "valid overrides",
+			overrides: []corev1alpha1.DataOverride{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"stage": "dev"}}, Data: map[string][]byte{"endpoint": []byte("dev.example.io")}},
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"stage": "test"}}, Data: map[string][]byte{"endpoint": []byte("test.example.io")}},
+			},
+			allowed: true,
+		},
+		{
+			name: "missing selector",
+			overrides: []corev1alpha1.DataOverride{
+				{Data: map[string][]byte{"endpoint": []byte("dev.example.io")}},
+			},
+			allowed: false,
+		},
+		{
+			name: "invalid selector",
+			overrides: []corev1alpha1.DataOverride{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"stage": "invalid value"}}, Data: map[string][]byte{"endpoint": []byte("dev.example.io")}},
+			},
+			allowed: false,
+		},
+		{
+			name: "invalid key",
+			overrides: []corev1alpha1.DataOverride{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"stage": "dev"}}, Data: map[string][]byte{"end/point": []byte("dev.example.io")}},
+			},
+			allowed: false,
+		},
+		{
+			name:      "invalid template",
+			templated: true,
+			overrides: []corev1alpha1.DataOverride{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"stage": "dev"}}, Data: map[string][]byte{"endpoint": []byte("{{ invalid")}},
+			},
+			allowed: false,
+		},
+	}
+
+	h := newHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{Templated: tt.templated})
+			clusterSecret.Spec.Overrides = tt.overrides
+			response := h.validate(context.TODO(), newAdmissionRequest(admissionv1.Create, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of namespace names (and conflicts with other clustersecrets)
+func TestValidateNamespaceNames(t *testing.T) {
+	existingClusterSecret := newClusterSecret("existing", corev1alpha1.SecretTemplateSpec{Name: "registry-credentials"})
+	existingClusterSecret.Spec.Namespaces = &corev1alpha1.NamespaceNames{
+		Include: []string{"team-*", "kube-system"},
+		Exclude: []string{"team-b"},
+	}
+
+	tests := []struct {
+		name       string
+		namespaces *corev1alpha1.NamespaceNames
+		allowed    bool
+	}{
+		{
+			name:       "invalid pattern",
+			namespaces: &corev1alpha1.NamespaceNames{Include: []string{"team-["}},
+			allowed:    false,
+		},
+		{
+			name:       "invalid name",
+			namespaces: &corev1alpha1.NamespaceNames{Exclude: []string{"Team_A"}},
+			allowed:    false,
+		},
+		{
+			name:       "overlapping pattern",
+			namespaces: &corev1alpha1.NamespaceNames{Include: []string{"*-a"}},
+			allowed:    false,
+		},
+		{
+			name:       "overlapping name",
+			namespaces: &corev1alpha1.NamespaceNames{Include: []string{"team-a"}},
+			allowed:    false,
+		},
+		{
+			name:       "disjoint names",
+			namespaces: &corev1alpha1.NamespaceNames{Include: []string{"default", "vendor-system"}},
+			allowed:    true,
+		},
+		{
+			name:       "disjoint by exclusion",
+			namespaces: &corev1alpha1.NamespaceNames{Include: []string{"team-b"}},
+			allowed:    true,
+		},
+		{
+			name:       "all except overlapping",
+			namespaces: &corev1alpha1.NamespaceNames{Exclude: []string{"team-*"}},
+			allowed:    false,
+		},
+	}
+
+	h := newHandler(t, existingClusterSecret)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{Name: "registry-credentials"})
+			clusterSecret.Spec.Namespaces = tt.namespaces
+			response := h.validate(context.TODO(), newAdmissionRequest(admissionv1.Create, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of deletion policy
+func TestValidateDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		deletionPolicy corev1alpha1.DeletionPolicy
+		allowed        bool
+	}{
+		{
+			name:           "default",
+			deletionPolicy: "",
+			allowed:        true,
+		},
+		{
+			name:           "delete",
+			deletionPolicy: corev1alpha1.DeletionPolicyDelete,
+			allowed:        true,
+		},
+		{
+			name:           "orphan",
+			deletionPolicy: corev1alpha1.DeletionPolicyOrphan,
+			allowed:        true,
+		},
+		{
+			name:           "invalid",
+			deletionPolicy: "Retain",
+			allowed:        false,
+		},
+	}
+
+	h := newHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{})
+			clusterSecret.Spec.DeletionPolicy = tt.deletionPolicy
+			response := h.validate(context.TODO(), newAdmissionRequest(admissionv1.Create, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of conflict policy
+func TestValidateConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		conflictPolicy corev1alpha1.ConflictPolicy
+		allowed        bool
+	}{
+		{
+			name:           "default",
+			conflictPolicy: "",
+			allowed:        true,
+		},
+		{
+			name:           "fail",
+			conflictPolicy: corev1alpha1.ConflictPolicyFail,
+			allowed:        true,
+		},
+		{
+			name:           "skip",
+			conflictPolicy: corev1alpha1.ConflictPolicySkip,
+			allowed:        true,
+		},
+		{
+			name:           "adopt",
+			conflictPolicy: corev1alpha1.ConflictPolicyAdopt,
+			allowed:        true,
+		},
+		{
+			name:           "invalid",
+			conflictPolicy: "Overwrite",
+			allowed:        false,
+		},
+	}
+
+	h := newHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{})
+			clusterSecret.Spec.ConflictPolicy = tt.conflictPolicy
+			response := h.validate(context.TODO(), newAdmissionRequest(admissionv1.Create, clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// test: validation of rollback annotation
+func TestValidateRollbackAnnotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   *string
+		allowed bool
+	}{
+		{
+			name:    "none",
+			value:   nil,
+			allowed: true,
+		},
+		{
+			name:    "valid",
+			value:   ptr("3"),
+			allowed: true,
+		},
+		{
+			name:    "zero",
+			value:   ptr("0"),
+			allowed: false,
+		},
+		{
+			name:    "invalid",
+			value:   ptr("previous"),
+			allowed: false,
+		},
+	}
+
+	h := newHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterSecret := newClusterSecret("my-secret", corev1alpha1.SecretTemplateSpec{})
+			if tt.value != nil {
+				clusterSecret.Annotations = map[string]string{common.AnnotationKeyRollbackTo: *tt.value}
+			}
+			response := h.validate(context.TODO(), newUpdateAdmissionRequest(clusterSecret.DeepCopy(), clusterSecret))
+			if response.Allowed != tt.allowed {
+				t.Errorf("expected allowed: %v, got: %v (%v)", tt.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,55 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package common holds the definitions shared by the controller and the admission webhook;
+// it must not depend on any other internal package.
+package common
+
+import (
+	"path"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+)
+
+const (
+	// used as finalizer on clustersecrets, and as field manager
+	ControllerName = "clustersecret-operator.cs.sap.com"
+)
+
+const (
+	// prefix of all label and annotation keys managed by the controller; keys with this prefix must not be set through the secret template
+	ReservedKeyPrefix       = "clustersecrets.core.cs.sap.com/"
+	LabelKeyName            = ReservedKeyPrefix + "name"
+	AnnotationKeyGeneration = ReservedKeyPrefix + "generation"
+	// set on managed secrets; hash of the rendered content of the secret, used for change detection (and usable by consumers,
+	// e.g. as checksum annotation on pod templates)
+	AnnotationKeyContentHash = ReservedKeyPrefix + "content-hash"
+	// set on clustersecrets by users, in order to roll back the template to the given revision (number)
+	AnnotationKeyRollbackTo = ReservedKeyPrefix + "rollback-to"
+	// set on revisions (of clustersecret templates)
+	LabelKeyTemplateHash    = ReservedKeyPrefix + "template-hash"
+	AnnotationKeyRecordedAt = ReservedKeyPrefix + "recorded-at"
+	// set on the payload secrets of revisions (instead of LabelKeyName, which marks managed secrets)
+	LabelKeyRevisionOf = ReservedKeyPrefix + "revision-of"
+)
+
+// return the name of the secrets managed by the specified clustersecret
+func GetSecretName(clusterSecret *corev1alpha1.ClusterSecret) string {
+	if clusterSecret.Spec.Template.Name != "" {
+		return clusterSecret.Spec.Template.Name
+	}
+	return clusterSecret.Name
+}
+
+// check if the specified namespace name matches any of the specified names or glob patterns
+func NamespaceNameMatchesAny(namespaceName string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// note: patterns are validated by the admission webhook, so errors can be ignored here
+		if ok, _ := path.Match(pattern, namespaceName); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/sap/clustersecret-operator/internal/common"
+	"github.com/sap/clustersecret-operator/internal/tracing"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+)
+
+const (
+	// maximum number of drifted namespaces listed in drift events
+	maxEventDriftedNamespaces = 10
+)
+
+func (c *Controller) startAuditor() {
+	if c.auditInterval <= 0 {
+		return
+	}
+	c.wgWorkers.Add(1)
+	go func() {
+		defer c.wgWorkers.Done()
+		logger := klog.FromContext(c.ctx).WithValues("auditor", true)
+		logger.V(1).Info("auditor starting", "interval", c.auditInterval)
+		ticker := time.NewTicker(c.auditInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.ctx.Done():
+				logger.V(1).Info("auditor exiting")
+				return
+			case <-ticker.C:
+				c.audit(klog.NewContext(c.ctx, logger))
+			}
+		}
+	}()
+}
+
+// compare all managed secrets against the secrets which would be generated from their clustersecrets, and report differences (drift)
+// per clustersecret, without fixing them; drift is reported as warning event, and in the status of the clustersecret
+func (c *Controller) audit(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	ctx, span := tracing.Tracer().Start(ctx, "audit")
+	defer span.End()
+
+	clusterSecrets, err := c.clusterSecretLister.List(labels.Everything())
+	if err != nil {
+		logger.Error(err, "error listing clustersecrets for audit")
+		tracing.RecordError(span, err)
+		return
+	}
+	numDrifted := 0
+	for _, clusterSecret := range clusterSecrets {
+		if !clusterSecret.DeletionTimestamp.IsZero() {
+			continue
+		}
+		logger := logger.WithValues("clustersecret", clusterSecret.Name)
+		driftedNamespaces, err := c.auditClusterSecret(klog.NewContext(ctx, logger), clusterSecret)
+		if err != nil {
+			logger.Error(err, "error auditing clustersecret")
+			continue
+		}
+		numDrifted += len(driftedNamespaces)
+		if len(driftedNamespaces) > 0 {
+			listedNamespaces := driftedNamespaces
+			if len(listedNamespaces) > maxEventDriftedNamespaces {
+				listedNamespaces = append(listedNamespaces[:maxEventDriftedNamespaces:maxEventDriftedNamespaces], "...")
+			}
+			c.warningEventf(EventVerbosityWarning, clusterSecret, "SecretDrift", "Secret %s was modified in %d namespace(s): %s", common.GetSecretName(clusterSecret), len(driftedNamespaces), strings.Join(listedNamespaces, ", "))
+		}
+		if int32(len(driftedNamespaces)) != clusterSecret.Status.DriftedNamespaces {
+			// note: the lister object must not be modified, so we have to work on a copy
+			clusterSecret = clusterSecret.DeepCopy()
+			newClusterSecret := clusterSecret.DeepCopy()
+			newClusterSecret.Status.DriftedNamespaces = int32(len(driftedNamespaces))
+			if err := c.writeClusterSecretStatus(ctx, clusterSecret, newClusterSecret); err != nil {
+				// the status will be updated by the next audit
+				logger.Error(err, "error updating clustersecret status after audit")
+			}
+		}
+	}
+	logger.V(1).Info("audit finished", "clustersecrets", len(clusterSecrets), "drifted", numDrifted)
+}
+
+// determine all selected namespaces where the managed secret of the given clustersecret differs from the wanted secret; secrets which are
+// not up-to-date because of a pending rollout (i.e. whose content hash differs from the wanted one) are not considered as drifted
+func (c *Controller) auditClusterSecret(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret) ([]string, error) {
+	logger := klog.FromContext(ctx)
+
+	var data map[string][]byte
+	if sourceRef := clusterSecret.Spec.SourceRef; sourceRef == nil {
+		data = clusterSecret.Spec.Template.Data
+	} else {
+		sourceSecret, err := c.getSourceSecret(ctx, sourceRef.Namespace, sourceRef.Name)
+		if errors.IsNotFound(err) {
+			// a missing source secret is reported by the reconciliation, not by the audit
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		data, err = buildDataFromSourceSecret(sourceSecret, sourceRef.Keys)
+		if err != nil {
+			return nil, nil
+		}
+	}
+
+	existingSecrets, err := c.getManagedSecrets(clusterSecret.Name)
+	if err != nil {
+		return nil, err
+	}
+	var driftedNamespaces []string
+	for _, existingSecret := range existingSecrets {
+		// secrets with a different name, or in namespaces which are no longer selected, are about to be deleted or orphaned by the next reconciliation
+		if existingSecret.Name != common.GetSecretName(clusterSecret) {
+			continue
+		}
+		namespace, err := c.namespaceLister.Get(existingSecret.Namespace)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if !namespace.DeletionTimestamp.IsZero() || !namespaceMatchesClusterSecret(namespace, clusterSecret) {
+			continue
+		}
+		secret, err := buildSecretFromClusterSecret(namespace, clusterSecret, data)
+		if err != nil {
+			// rendering errors are reported by the reconciliation, not by the audit
+			continue
+		}
+		// a content hash differing from the wanted one means that the secret was written from a different template; this is only accepted
+		// as long as a rollout is pending, i.e. if the current generation of the clustersecret was not yet reconciled (successfully) in the namespace
+		// of the secret; otherwise, a missing, invalid or differing content hash is reported as drift (as any other difference)
+		// note: the generation annotation cannot be used for this, since changes not affecting the rendered secret (e.g. of the namespace selector)
+		// increase the generation of the clustersecret without rewriting the secrets; a missing or invalid generation annotation is reported as drift
+		if existingSecret.Annotations[common.AnnotationKeyContentHash] != secret.Annotations[common.AnnotationKeyContentHash] && isRolloutPending(clusterSecret, existingSecret.Namespace) {
+			continue
+		}
+		if differences := getSecretDifferences(existingSecret, secret); len(differences) > 0 {
+			logger.V(1).Info("detected drift", "namespace", existingSecret.Namespace, "secret", existingSecret.Name, "differences", differences)
+			driftedNamespaces = append(driftedNamespaces, existingSecret.Namespace)
+		}
+	}
+	sort.Strings(driftedNamespaces)
+	return driftedNamespaces, nil
+}
+
+// check if a rollout of the current generation of the given clustersecret may still be pending in the given namespace, i.e. if that generation
+// was not yet reconciled, or reconciling the secret in that namespace failed
+func isRolloutPending(clusterSecret *corev1alpha1.ClusterSecret, namespace string) bool {
+	if clusterSecret.Status.ObservedGeneration < clusterSecret.Generation {
+		return true
+	}
+	for _, failure := range clusterSecret.Status.Failures {
+		if failure.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -8,21 +8,35 @@ package controller
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes" // could also be aliased 'kubeclients' but we keep it as 'kubernetes' since most people do
 	kubescheme "k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	kubecorev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/metadata/metadatalister"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"github.com/sap/clustersecret-operator/internal/common"
+	"github.com/sap/clustersecret-operator/internal/logging"
+	"github.com/sap/clustersecret-operator/internal/metrics"
+	"github.com/sap/clustersecret-operator/internal/tracing"
+
 	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 	coreclients "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned"
 	corescheme "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned/scheme"
@@ -30,27 +44,68 @@ import (
 	corev1alpha1listers "github.com/sap/clustersecret-operator/pkg/client/listers/core.cs.sap.com/v1alpha1"
 )
 
-const (
-	ControllerName = "clustersecret-operator.cs.sap.com"
-)
-
 type Controller struct {
-	ctx                   context.Context                         // controller context; controller will terminate when context is cancelled
-	kubeclient            kubernetes.Interface                    // kubernetes client; use client interface, so we can mock it (e.g. with the fake client)
-	coreclient            coreclients.Interface                   // core client; use client interface, so we can mock it (e.g. with the fake client)
-	kubeinformerFactory   kubeinformers.SharedInformerFactory     // kubernetes informer factory
-	coreinformerFactory   coreinformers.SharedInformerFactory     // core informer factory
-	namespaceInformer     cache.SharedIndexInformer               // namespace informer
-	secretInformer        cache.SharedIndexInformer               // secret informer
-	clusterSecretInformer cache.SharedIndexInformer               // clustersecret informer
-	namespaceLister       kubecorev1listers.NamespaceLister       // namespace lister
-	secretLister          kubecorev1listers.SecretLister          // secret lister
-	clusterSecretLister   corev1alpha1listers.ClusterSecretLister // clustersecret lister
-	eventRecorder         record.EventRecorder                    // event recorder
-	workqueue             workqueue.RateLimitingInterface         // workqueue
-	numWorkers            int                                     // number of worker routines
-	wgWorkers             sync.WaitGroup                          // wait group to be able to work for workers to complete
-	synchronizer          Synchronizer                            // cache synchronizer
+	ctx                     context.Context                         // controller context; controller will terminate when context is cancelled
+	kubeclient              kubernetes.Interface                    // kubernetes client; use client interface, so we can mock it (e.g. with the fake client)
+	metadataclient          metadata.Interface                      // metadata client (for watching unmanaged secrets); use client interface, so we can mock it
+	coreclient              coreclients.Interface                   // core client; use client interface, so we can mock it (e.g. with the fake client)
+	kubeinformerFactory     kubeinformers.SharedInformerFactory     // kubernetes informer factory
+	secretInformerFactory   kubeinformers.SharedInformerFactory     // informer factory for managed secrets
+	metadataInformerFactory metadatainformer.SharedInformerFactory  // metadata informer factory (for all secrets)
+	coreinformerFactory     coreinformers.SharedInformerFactory     // core informer factory
+	namespaceInformer       cache.SharedIndexInformer               // namespace informer
+	secretInformer          cache.SharedIndexInformer               // managed secret informer
+	secretMetadataInformer  cache.SharedIndexInformer               // secret metadata informer (for all secrets, caching their metadata only)
+	clusterSecretInformer   cache.SharedIndexInformer               // clustersecret informer
+	namespaceLister         kubecorev1listers.NamespaceLister       // namespace lister
+	secretLister            kubecorev1listers.SecretLister          // managed secret lister
+	secretMetadataLister    metadatalister.Lister                   // secret metadata lister (for all secrets, returning their metadata only)
+	clusterSecretLister     corev1alpha1listers.ClusterSecretLister // clustersecret lister
+	eventRecorder           record.EventRecorder                    // event recorder
+	workqueue               *trackingQueue                          // workqueue
+	numWorkers              int                                     // number of worker routines
+	wgWorkers               sync.WaitGroup                          // wait group to be able to work for workers to complete
+	synchronizer            Synchronizer                            // cache synchronizer
+	leading                 atomic.Bool                             // whether the controller was started (i.e. is leading)
+	informersSynced         atomic.Bool                             // whether the informer caches are synced
+	lastProgress            atomic.Int64                            // time (unix nanoseconds) when some worker made progress the last time
+	reconcileInfos          map[string]*reconcileInfo               // outcome of the last reconciliation per clustersecret (for debugging)
+	reconcileInfosMutex     sync.Mutex                              // mutex protecting reconcileInfos
+	eventVerbosity          EventVerbosity                          // which events to emit
+	revisionInformerFactory kubeinformers.SharedInformerFactory     // informer factory for revisions (nil if revision history is disabled)
+	revisionLister          appsv1listers.ControllerRevisionLister  // revision lister (nil if revision history is disabled)
+	revisionNamespace       string                                  // namespace where revisions are stored
+	revisionHistoryLimit    int                                     // number of revisions kept per clustersecret
+	auditInterval           time.Duration                           // interval of drift audits (disabled if zero)
+	selfHealing             bool                                    // whether managed secrets modified or deleted by someone else are repaired immediately
+	secretWriteConcurrency  int                                     // maximum number of secrets written in parallel (per reconciliation)
+}
+
+// Options for the controller; zero (or otherwise invalid, such as negative) values are replaced by the according defaults
+type Options struct {
+	// which events to emit (default: summary)
+	EventVerbosity EventVerbosity
+	// namespace where revisions of clustersecret templates are stored (default: empty, i.e. revision history is disabled)
+	RevisionNamespace string
+	// number of revisions kept per clustersecret (default: 10)
+	RevisionHistoryLimit int
+	// interval in which managed secrets are audited for drift (default: zero, i.e. auditing is disabled)
+	AuditInterval time.Duration
+	// repair managed secrets immediately when they are modified or deleted by someone else (default: false, i.e. self-healing is disabled)
+	SelfHealing bool
+	// maximum number of secrets written (created, updated, deleted) in parallel by one reconciliation of a clustersecret
+	// (default: 10; values less than one are replaced by the default)
+	SecretWriteConcurrency int
+	// number of worker routines (default: 3)
+	Workers int
+	// resync period of the informers (default: 5 minutes)
+	ResyncPeriod time.Duration
+	// base and maximum delay of the per-item exponential backoff applied when requeuing failed items (default: 5 milliseconds, 1000 seconds)
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// rate (per second) and burst of the overall token bucket limiting requeues of failed items (default: 10, 100)
+	BucketQPS   float64
+	BucketBurst int
 }
 
 type workqueueItem struct {
@@ -63,38 +118,145 @@ const (
 	workqueueItemKeyClusterSecret
 )
 
-func NewController(ctx context.Context, kubeclient kubernetes.Interface, coreclient coreclients.Interface, synchronizer Synchronizer) *Controller {
+func (i workqueueItem) kind() string {
+	switch i.key {
+	case workqueueItemKeyNamespace:
+		return "namespace"
+	case workqueueItemKeyClusterSecret:
+		return "clustersecret"
+	default:
+		panic("this cannot happen")
+	}
+}
+
+func NewController(ctx context.Context, kubeclient kubernetes.Interface, metadataclient metadata.Interface, coreclient coreclients.Interface, synchronizer Synchronizer, options Options) *Controller {
+	// apply defaults
+	if options.EventVerbosity == "" {
+		options.EventVerbosity = EventVerbositySummary
+	}
+	if options.RevisionHistoryLimit <= 0 {
+		options.RevisionHistoryLimit = 10
+	}
+	if options.SecretWriteConcurrency <= 0 {
+		options.SecretWriteConcurrency = 10
+	}
+	if options.Workers <= 0 {
+		options.Workers = 3
+	}
+	if options.ResyncPeriod <= 0 {
+		options.ResyncPeriod = 300 * time.Second
+	}
+	if options.BaseBackoff <= 0 {
+		options.BaseBackoff = 5 * time.Millisecond
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 1000 * time.Second
+	}
+	if options.MaxBackoff < options.BaseBackoff {
+		options.MaxBackoff = options.BaseBackoff
+	}
+	if options.BucketQPS <= 0 {
+		options.BucketQPS = 10
+	}
+	if options.BucketBurst <= 0 {
+		options.BucketBurst = 100
+	}
+
 	// kubernetes client (for namespaces, secrets)
-	kubeinformerFactory := kubeinformers.NewSharedInformerFactory(kubeclient, 300*time.Second)
+	// note: in order to limit memory usage, managed secrets (carrying our name label) are the only secrets which are fully listed, watched
+	// and cached; all other secrets (which have to be watched in order to detect changes of source secrets, and conflicts) are only
+	// listed and watched through the metadata client, such that their data is never transferred to (or decoded by) the controller;
+	// in addition, managed fields are never cached
+	kubeinformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(
+		kubeclient,
+		options.ResyncPeriod,
+		kubeinformers.WithTransform(stripManagedFields),
+	)
 	nsInformer := kubeinformerFactory.Core().V1().Namespaces()
-	scInformer := kubeinformerFactory.Core().V1().Secrets()
+	metadataInformerFactory := metadatainformer.NewSharedInformerFactoryWithOptions(
+		metadataclient,
+		options.ResyncPeriod,
+		metadatainformer.WithTransform(stripManagedFields),
+	)
+	smInformer := metadataInformerFactory.ForResource(corev1.SchemeGroupVersion.WithResource("secrets"))
+	secretInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(
+		kubeclient,
+		options.ResyncPeriod,
+		kubeinformers.WithTweakListOptions(func(listOptions *metav1.ListOptions) {
+			listOptions.LabelSelector = common.LabelKeyName
+		}),
+		kubeinformers.WithTransform(stripManagedFields),
+	)
+	scInformer := secretInformerFactory.Core().V1().Secrets()
 	// attention: important to create informer and lister before starting the factory !!!
 	namespaceInformer := nsInformer.Informer()
 	namespaceLister := nsInformer.Lister()
+	secretMetadataInformer := smInformer.Informer()
+	secretMetadataLister := metadatalister.New(secretMetadataInformer.GetIndexer(), corev1.SchemeGroupVersion.WithResource("secrets"))
 	secretInformer := scInformer.Informer()
 	secretLister := scInformer.Lister()
 
 	// core client (for our custom resources, i.e. for clustersecrets)
-	coreinformerFactory := coreinformers.NewSharedInformerFactory(coreclient, 300*time.Second)
+	coreinformerFactory := coreinformers.NewSharedInformerFactory(coreclient, options.ResyncPeriod)
 	csInformer := coreinformerFactory.Core().V1alpha1().ClusterSecrets()
 	// attention: important to create informer and lister before starting the factory !!!
 	clusterSecretInformer := csInformer.Informer()
 	clusterSecretLister := csInformer.Lister()
+	// index clustersecrets by their source secret and their conflicting secrets, in order to efficiently find the clustersecrets affected by secret changes;
+	// in addition, index them by the label keys required by their namespace selector, in order to efficiently find the clustersecrets selecting a namespace
+	if err := clusterSecretInformer.AddIndexers(cache.Indexers{
+		indexSourceSecret:      indexClusterSecretBySourceSecret,
+		indexConflictingSecret: indexClusterSecretByConflictingSecret,
+		indexNamespaceLabelKey: indexClusterSecretByNamespaceLabelKey,
+	}); err != nil {
+		panic("this cannot happen")
+	}
+	// index managed secrets by their owning clustersecret
+	if err := secretInformer.AddIndexers(cache.Indexers{
+		indexOwner: indexSecretByOwner,
+	}); err != nil {
+		panic("this cannot happen")
+	}
+
+	// revisions of clustersecret templates (if enabled); only revisions in the configured namespace, carrying our name label, are watched
+	var revisionInformerFactory kubeinformers.SharedInformerFactory
+	var revisionInformer cache.SharedIndexInformer
+	var revisionLister appsv1listers.ControllerRevisionLister
+	if options.RevisionNamespace != "" {
+		revisionInformerFactory = kubeinformers.NewSharedInformerFactoryWithOptions(
+			kubeclient,
+			options.ResyncPeriod,
+			kubeinformers.WithNamespace(options.RevisionNamespace),
+			kubeinformers.WithTweakListOptions(func(listOptions *metav1.ListOptions) {
+				listOptions.LabelSelector = common.LabelKeyName
+			}),
+		)
+		crInformer := revisionInformerFactory.Apps().V1().ControllerRevisions()
+		// attention: important to create informer and lister before starting the factory !!!
+		revisionInformer = crInformer.Informer()
+		revisionLister = crInformer.Lister()
+	}
 
 	// setup event recorder
 	scheme := runtime.NewScheme()
 	kubescheme.AddToScheme(scheme)
 	corescheme.AddToScheme(scheme)
 	eventBroadcaster := record.NewBroadcaster()
-	eventBroadcaster.StartLogging(klog.V(3).Infof)
+	eventBroadcaster.StartStructuredLogging(3)
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclient.CoreV1().Events("")})
-	eventRecorder := eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: ControllerName})
+	eventRecorder := eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: common.ControllerName})
 
-	// setup workqueue
-	workqueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "")
+	// setup workqueue; the rate limiter is built like workqueue.DefaultControllerRateLimiter(), that is, as combination of a per-item exponential backoff,
+	// and an overall token bucket, but with configurable parameters
+	// note: the queue name is used as label value of the workqueue metrics
+	rateLimiter := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(options.BaseBackoff, options.MaxBackoff),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(options.BucketQPS), options.BucketBurst)},
+	)
+	workqueue := newTrackingQueue(workqueue.NewNamedRateLimitingQueue(rateLimiter, "clustersecrets"))
 	go func() {
 		<-ctx.Done()
-		klog.V(1).Info("shutting down work queue")
+		klog.FromContext(ctx).V(1).Info("shutting down work queue")
 		workqueue.ShutDown()
 	}()
 
@@ -105,33 +267,53 @@ func NewController(ctx context.Context, kubeclient kubernetes.Interface, corecli
 		informers[schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"}] = namespaceInformer
 		informers[schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"}] = secretInformer
 		informers[corev1alpha1.ClusterSecretGroupVersionKind] = clusterSecretInformer
+		if revisionInformer != nil {
+			informers[controllerRevisionGroupVersionKind] = revisionInformer
+		}
 		synchronizer.Init(informers)
 	}
 
 	return &Controller{
-		ctx:                   ctx,
-		kubeclient:            kubeclient,
-		coreclient:            coreclient,
-		kubeinformerFactory:   kubeinformerFactory,
-		coreinformerFactory:   coreinformerFactory,
-		namespaceInformer:     namespaceInformer,
-		secretInformer:        secretInformer,
-		clusterSecretInformer: clusterSecretInformer,
-		namespaceLister:       namespaceLister,
-		secretLister:          secretLister,
-		clusterSecretLister:   clusterSecretLister,
-		eventRecorder:         eventRecorder,
-		workqueue:             workqueue,
-		numWorkers:            3, // todo: make configurable
-		synchronizer:          synchronizer,
+		ctx:                     ctx,
+		kubeclient:              kubeclient,
+		metadataclient:          metadataclient,
+		coreclient:              coreclient,
+		kubeinformerFactory:     kubeinformerFactory,
+		secretInformerFactory:   secretInformerFactory,
+		metadataInformerFactory: metadataInformerFactory,
+		coreinformerFactory:     coreinformerFactory,
+		namespaceInformer:       namespaceInformer,
+		secretInformer:          secretInformer,
+		secretMetadataInformer:  secretMetadataInformer,
+		clusterSecretInformer:   clusterSecretInformer,
+		namespaceLister:         namespaceLister,
+		secretLister:            secretLister,
+		secretMetadataLister:    secretMetadataLister,
+		clusterSecretLister:     clusterSecretLister,
+		eventRecorder:           eventRecorder,
+		workqueue:               workqueue,
+		numWorkers:              options.Workers,
+		synchronizer:            synchronizer,
+		reconcileInfos:          make(map[string]*reconcileInfo),
+		eventVerbosity:          options.EventVerbosity,
+		revisionInformerFactory: revisionInformerFactory,
+		revisionLister:          revisionLister,
+		revisionNamespace:       options.RevisionNamespace,
+		revisionHistoryLimit:    options.RevisionHistoryLimit,
+		auditInterval:           options.AuditInterval,
+		selfHealing:             options.SelfHealing,
+		secretWriteConcurrency:  options.SecretWriteConcurrency,
 	}
 }
 
 // this method should not be called more than once on the same receiver; todo: safeguard with some lock
 func (c *Controller) Start() {
+	c.leading.Store(true)
+	c.recordProgress()
 	c.startEventHandlers()
 	c.startWorkers()
 	c.startInformers()
+	c.startAuditor()
 }
 
 func (c *Controller) Wait() {
@@ -143,15 +325,36 @@ func (c *Controller) startInformers() {
 	c.kubeinformerFactory.Start(c.ctx.Done())
 	for _, ok := range c.kubeinformerFactory.WaitForCacheSync(c.ctx.Done()) {
 		if !ok {
-			klog.Fatal("error waiting for informer caches to sync")
+			logging.Fatal(nil, "error waiting for informer caches to sync")
+		}
+	}
+	c.secretInformerFactory.Start(c.ctx.Done())
+	for _, ok := range c.secretInformerFactory.WaitForCacheSync(c.ctx.Done()) {
+		if !ok {
+			logging.Fatal(nil, "error waiting for informer caches to sync")
+		}
+	}
+	c.metadataInformerFactory.Start(c.ctx.Done())
+	for _, ok := range c.metadataInformerFactory.WaitForCacheSync(c.ctx.Done()) {
+		if !ok {
+			logging.Fatal(nil, "error waiting for informer caches to sync")
 		}
 	}
 	c.coreinformerFactory.Start(c.ctx.Done())
 	for _, ok := range c.coreinformerFactory.WaitForCacheSync(c.ctx.Done()) {
 		if !ok {
-			klog.Fatal("error waiting for informer caches to sync")
+			logging.Fatal(nil, "error waiting for informer caches to sync")
 		}
 	}
+	if c.revisionInformerFactory != nil {
+		c.revisionInformerFactory.Start(c.ctx.Done())
+		for _, ok := range c.revisionInformerFactory.WaitForCacheSync(c.ctx.Done()) {
+			if !ok {
+				logging.Fatal(nil, "error waiting for informer caches to sync")
+			}
+		}
+	}
+	c.informersSynced.Store(true)
 }
 
 func (c *Controller) startEventHandlers() {
@@ -167,6 +370,60 @@ func (c *Controller) startEventHandlers() {
 			// DeleteFunc: c.enqueueNamespace,
 		},
 	)
+	// all secrets are watched in order to find clustersecrets using them as source secret, or reporting them as conflicting
+	c.secretMetadataInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(new interface{}) {
+				c.enqueueClusterSecretsForSecret("ADD", new)
+			},
+			UpdateFunc: func(old, new interface{}) {
+				oldSecret, ok := old.(*metav1.PartialObjectMetadata)
+				if !ok {
+					panic("this cannot happen")
+				}
+				newSecret, ok := new.(*metav1.PartialObjectMetadata)
+				if !ok {
+					panic("this cannot happen")
+				}
+				// skip periodic resyncs
+				if oldSecret.ResourceVersion != newSecret.ResourceVersion {
+					c.enqueueClusterSecretsForSecret("UPDATE", new)
+				}
+			},
+			DeleteFunc: func(old interface{}) {
+				c.enqueueClusterSecretsForSecret("DELETE", old)
+			},
+		},
+	)
+	// secrets managed by us are watched (with the managed secret informer) in order to repair them if modified or deleted by someone else
+	if c.selfHealing {
+		c.secretInformer.AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(old, new interface{}) {
+					oldSecret, ok := old.(*corev1.Secret)
+					if !ok {
+						panic("this cannot happen")
+					}
+					newSecret, ok := new.(*corev1.Secret)
+					if !ok {
+						panic("this cannot happen")
+					}
+					// skip periodic resyncs
+					if oldSecret.ResourceVersion != newSecret.ResourceVersion {
+						// note: if the managing clustersecret was changed (e.g. the label removed), the previous one has to repair the secret
+						if oldSecret.Labels[common.LabelKeyName] != newSecret.Labels[common.LabelKeyName] {
+							c.enqueueClusterSecretForManagedSecret("UPDATE", old)
+						}
+						c.enqueueClusterSecretForManagedSecret("UPDATE", new)
+					}
+				},
+				// note: secrets whose name label is removed are reported as deleted by the (label filtered) informer
+				DeleteFunc: func(old interface{}) {
+					c.enqueueClusterSecretForManagedSecret("DELETE", old)
+				},
+			},
+		)
+	}
 	c.clusterSecretInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
 			AddFunc: func(new interface{}) {
@@ -181,7 +438,9 @@ func (c *Controller) startEventHandlers() {
 				if !ok {
 					panic("this cannot happen")
 				}
-				if oldClusterSecret.Generation != newClusterSecret.Generation {
+				// note: rollbacks are requested through an annotation (which does not change the generation)
+				_, rollback := newClusterSecret.Annotations[common.AnnotationKeyRollbackTo]
+				if oldClusterSecret.Generation != newClusterSecret.Generation || rollback {
 					c.enqueueClusterSecret("UPDATE", new)
 				}
 			},
@@ -198,15 +457,17 @@ func (c *Controller) startWorkers() {
 		c.wgWorkers.Add(1)
 		go func(i int) {
 			defer c.wgWorkers.Done()
-			klog.V(1).Infof("worker %d starting", i)
+			logger := klog.FromContext(c.ctx).WithValues("worker", i)
+			logger.V(1).Info("worker starting")
 			for {
 				// get object from queue; will block as long as queue is empty
 				// note: due to the implementation of the workqueue it is guaranteed that an item cannot be processed by more than one worker at the same time
 				obj, shutdown := c.workqueue.Get()
 				if shutdown {
-					klog.V(1).Infof("worker %d exiting", i)
+					logger.V(1).Info("worker exiting")
 					return
 				}
+				c.recordProgress()
 				// cast to workqueueItem (we know that there cannot be anything different in the queue)
 				item, ok := obj.(workqueueItem)
 				if !ok {
@@ -214,24 +475,40 @@ func (c *Controller) startWorkers() {
 				}
 				// process item; use an anonymous func to easily ensure calling Done() for the item
 				func(item workqueueItem) {
+					defer c.recordProgress()
 					defer c.workqueue.Done(item)
+					// attach a logger identifying the item and this particular reconciliation to the context passed to the reconcile functions
+					reconcileID := uuid.New().String()
+					ctx, span := c.startReconcileSpan(c.ctx, item, i, reconcileID)
+					defer span.End()
+					logger := logger.WithValues(item.kind(), item.name, "reconcileID", reconcileID).WithValues(tracing.LogValues(ctx)...)
+					ctx = klog.NewContext(ctx, logger)
 					switch item.key {
 					case workqueueItemKeyNamespace:
-						if err := c.reconcileNamespace(item.name); err != nil {
+						start := time.Now()
+						err := c.reconcileNamespace(ctx, item.name)
+						metrics.ObserveReconcile(item.kind(), time.Since(start), err)
+						tracing.RecordError(span, err)
+						if err != nil {
 							c.workqueue.AddRateLimited(item)
-							klog.Errorf("error reconciling namespace %s: %s (requeuing)", item.name, err)
+							logger.Error(err, "error reconciling namespace (requeuing)")
 							return
 						}
 						c.workqueue.Forget(item)
-						klog.V(2).Infof("successfully reconciled namespace %s", item.name)
+						logger.V(2).Info("successfully reconciled namespace")
 					case workqueueItemKeyClusterSecret:
-						if err := c.reconcileClusterSecret(item.name); err != nil {
+						start := time.Now()
+						err := c.reconcileClusterSecret(ctx, item.name)
+						metrics.ObserveReconcile(item.kind(), time.Since(start), err)
+						tracing.RecordError(span, err)
+						c.recordReconcile(item.name, start, err)
+						if err != nil {
 							c.workqueue.AddRateLimited(item)
-							klog.Errorf("error reconciling clustersecret %s: %s (requeuing)", item.name, err)
+							logger.Error(err, "error reconciling clustersecret (requeuing)")
 							return
 						}
 						c.workqueue.Forget(item)
-						klog.V(2).Infof("successfully reconciled clustersecret %s", item.name)
+						logger.V(2).Info("successfully reconciled clustersecret")
 					default:
 						panic("this cannot happen")
 					}
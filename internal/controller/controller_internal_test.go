@@ -0,0 +1,52 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sap/clustersecret-operator/test"
+)
+
+// test: options (and their defaults)
+func TestOptions(t *testing.T) {
+	env := test.NewEnvironment()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, Options{})
+	if c.numWorkers != 3 {
+		t.Errorf("expected 3 workers by default, got %d", c.numWorkers)
+	}
+	if c.secretWriteConcurrency != 10 {
+		t.Errorf("expected secret write concurrency 10 by default, got %d", c.secretWriteConcurrency)
+	}
+	if c.eventVerbosity != EventVerbositySummary {
+		t.Errorf("expected event verbosity %s by default, got %s", EventVerbositySummary, c.eventVerbosity)
+	}
+
+	c = NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, Options{Workers: 7, SecretWriteConcurrency: 20})
+	if c.numWorkers != 7 {
+		t.Errorf("expected 7 workers, got %d", c.numWorkers)
+	}
+	if c.secretWriteConcurrency != 20 {
+		t.Errorf("expected secret write concurrency 20, got %d", c.secretWriteConcurrency)
+	}
+
+	c = NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, Options{SecretWriteConcurrency: -1, Workers: -1, RevisionHistoryLimit: -1, ResyncPeriod: -time.Second, BaseBackoff: -time.Second, MaxBackoff: -time.Second, BucketQPS: -1, BucketBurst: -1})
+	if c.secretWriteConcurrency != 10 {
+		t.Errorf("expected secret write concurrency 10 for negative value, got %d", c.secretWriteConcurrency)
+	}
+	if c.numWorkers != 3 {
+		t.Errorf("expected 3 workers for negative value, got %d", c.numWorkers)
+	}
+	if c.revisionHistoryLimit != 10 {
+		t.Errorf("expected revision history limit 10 for negative value, got %d", c.revisionHistoryLimit)
+	}
+}
@@ -8,8 +8,17 @@ package controller_test
 import (
 	"context"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/sap/clustersecret-operator/internal/controller"
+	"github.com/sap/clustersecret-operator/internal/tracing"
 	"github.com/sap/clustersecret-operator/test"
 )
 
@@ -23,7 +32,7 @@ func TestController1(t *testing.T) {
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	c := controller.NewController(ctx, env.KubernetesClient(), env.CoreClient(), nil)
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
 	c.Start()
 	defer c.Wait()
 	defer cancel()
@@ -51,7 +60,7 @@ func TestController2(t *testing.T) {
 	clusterSecret := env.LoadClusterSecretFromFile("clustersecret.yaml")
 
 	ctx, cancel := context.WithCancel(context.Background())
-	c := controller.NewController(ctx, env.KubernetesClient(), env.CoreClient(), nil)
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
 	c.Start()
 	defer c.Wait()
 	defer cancel()
@@ -96,7 +105,7 @@ func TestController3(t *testing.T) {
 	clusterSecret_a := env.LoadClusterSecretFromFile("clustersecret-a.yaml")
 
 	ctx, cancel := context.WithCancel(context.Background())
-	c := controller.NewController(ctx, env.KubernetesClient(), env.CoreClient(), nil)
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
 	c.Start()
 	defer c.Wait()
 	defer cancel()
@@ -140,7 +149,7 @@ func TestController4(t *testing.T) {
 	secret_b_2 := env.MustFatal(t).GetSecret("my-namespace-2", "my-secret-b")
 
 	ctx, cancel := context.WithCancel(context.Background())
-	c := controller.NewController(ctx, env.KubernetesClient(), env.CoreClient(), nil)
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
 	c.Start()
 	defer c.Wait()
 	defer cancel()
@@ -156,3 +165,321 @@ func TestController4(t *testing.T) {
 	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name", 1)
 	env.MustError(t).AssertSecret(secret_b_2)
 }
+
+// test: templated clustersecrets
+func TestController5(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/5")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+	)
+
+	clusterSecret := env.LoadClusterSecretFromFile("clustersecret.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
+
+	env.MustFatal(t).LabelNamespace("my-namespace-2", "tier", "test")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2-updated.yaml")
+}
+
+// test: clustersecrets with custom secret labels and annotations
+func TestController6(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/7")
+
+	env.AddObjectsFromFiles(
+		"namespace.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret := env.MustFatal(t).CreateClusterSecretFromFile("clustersecret.yaml")
+	clusterSecret = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretFromFile("secret.yaml")
+
+	env.MustFatal(t).UpdateClusterSecretFromFile("clustersecret-updated.yaml")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretFromFile("secret-updated.yaml")
+}
+
+// test: clustersecrets with custom secret name
+func TestController7(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/8")
+
+	env.AddObjectsFromFiles(
+		"clustersecret-a.yaml",
+		"clustersecret-b.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+	)
+
+	clusterSecret_a := env.LoadClusterSecretFromFile("clustersecret-a.yaml")
+	clusterSecret_b := env.LoadClusterSecretFromFile("clustersecret-b.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret_a = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret_a)
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret_b)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name", 2)
+	env.MustError(t).AssertSecretFromFile("secret-a-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-b-2.yaml")
+
+	env.MustFatal(t).UnlabelNamespace("my-namespace-1", "stage")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret_a)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name", 1)
+	env.MustError(t).AssertSecretFromFile("secret-b-2.yaml")
+}
+
+// test: clustersecrets with source secret
+func TestController8(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/9")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace.yaml",
+		"namespace-platform.yaml",
+		"source.yaml",
+	)
+
+	clusterSecret := env.LoadClusterSecretFromFile("clustersecret.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret.yaml")
+
+	env.MustFatal(t).UpdateSecretFromFile("source-updated.yaml")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret-updated.yaml")
+}
+
+// test: selection of namespaces by name lists and glob patterns
+func TestController9(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/11")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-team-a.yaml",
+		"namespace-team-b.yaml",
+		"namespace-kube-system.yaml",
+		"namespace-default.yaml",
+	)
+
+	clusterSecret := env.LoadClusterSecretFromFile("clustersecret.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-team-a.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-kube-system.yaml")
+
+	env.MustFatal(t).CreateNamespaceFromFile("namespace-team-c.yaml")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 3)
+	env.MustError(t).AssertSecretFromFile("secret-team-c.yaml")
+}
+
+// test: orphan secrets (deletion policy Orphan)
+func TestController10(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/12")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+	)
+
+	clusterSecret := env.LoadClusterSecretFromFile("clustersecret.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
+
+	env.MustFatal(t).UnlabelNamespace("my-namespace-1", "stage")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret-1-orphaned.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
+}
+
+// test: resolve conflicts with existing unmanaged secrets
+func TestController11(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/13")
+
+	env.AddObjectsFromFiles(
+		"clustersecret-skip.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+		"secret-2-unmanaged.yaml",
+	)
+
+	clusterSecret := env.LoadClusterSecretFromFile("clustersecret-skip.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret-2-unmanaged.yaml")
+
+	env.MustFatal(t).DeleteSecret("my-namespace-2", "my-secret")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+}
+
+// test: tracing of reconciliations
+func TestController12(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/15")
+
+	env.AddObjectsFromFiles(
+		"namespace.yaml",
+	)
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	defer tracing.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret := env.MustFatal(t).CreateClusterSecretFromFile("clustersecret.yaml")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretFromFile("secret.yaml")
+
+	// stop controller, to make sure that all spans are ended
+	cancel()
+	c.Wait()
+
+	var reconcileSpan, secretSpan sdktrace.ReadOnlySpan
+	for _, span := range spanRecorder.Ended() {
+		switch {
+		case span.Name() == "reconcile clustersecret" && reconcileSpan == nil:
+			reconcileSpan = span
+		case span.Name() == "reconcile secret" && secretSpan == nil:
+			secretSpan = span
+		}
+	}
+	if reconcileSpan == nil || secretSpan == nil {
+		t.Fatalf("expected spans not recorded")
+	}
+	if reconcileSpan.Parent().IsValid() {
+		t.Errorf("reconcile span unexpectedly has a parent: %s", reconcileSpan.Parent().SpanID())
+	}
+	if secretSpan.Parent().SpanID() != reconcileSpan.SpanContext().SpanID() {
+		t.Errorf("secret span is not a child of the reconcile span")
+	}
+	expectedAttributes := map[attribute.Key]string{
+		tracing.AttributeKeyNamespace: "my-namespace",
+		tracing.AttributeKeySecret:    "my-secret",
+		tracing.AttributeKeyOperation: "create",
+	}
+	for _, attribute := range secretSpan.Attributes() {
+		if value, ok := expectedAttributes[attribute.Key]; ok {
+			if attribute.Value.AsString() != value {
+				t.Errorf("unexpected value of attribute %s; expected: %s, actual: %s", attribute.Key, value, attribute.Value.AsString())
+			}
+			delete(expectedAttributes, attribute.Key)
+		}
+	}
+	if len(expectedAttributes) > 0 {
+		t.Errorf("missing attributes on secret span: %v", expectedAttributes)
+	}
+}
+
+// test: self-healing of modified or deleted secrets
+func TestController13(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/1")
+
+	env.AddObjectsFromFiles(
+		"namespace.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := controller.NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), nil, controller.Options{SelfHealing: true})
+	c.Start()
+	defer c.Wait()
+	defer cancel()
+
+	clusterSecret := env.MustFatal(t).CreateClusterSecretFromFile("clustersecret.yaml")
+	_ = env.MustFatal(t).WaitForClusterSecretReady(clusterSecret)
+	env.MustError(t).AssertSecretFromFile("secret.yaml")
+
+	waitForSecretRepaired := func() {
+		t.Helper()
+		if err := wait.PollUntilContextTimeout(ctx, 50*time.Millisecond, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+			return env.AssertSecretFromFile("secret.yaml") == nil, nil
+		}); err != nil {
+			t.Fatalf("secret was not repaired: %s", err)
+		}
+	}
+
+	// modify data
+	secret := env.MustFatal(t).GetSecret("my-namespace", "my-secret")
+	secret.Data["mykey"] = []byte("modified")
+	env.MustFatal(t).UpdateSecret(secret)
+	waitForSecretRepaired()
+
+	// modify annotations
+	secret = env.MustFatal(t).GetSecret("my-namespace", "my-secret")
+	secret.Annotations["clustersecrets.core.cs.sap.com/generation"] = "invalid"
+	env.MustFatal(t).UpdateSecret(secret)
+	waitForSecretRepaired()
+
+	// delete secret
+	env.MustFatal(t).DeleteSecret("my-namespace", "my-secret")
+	waitForSecretRepaired()
+}
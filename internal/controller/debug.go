@@ -0,0 +1,229 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	queueItemStateQueued     = "Queued"
+	queueItemStateWaiting    = "Waiting"
+	queueItemStateProcessing = "Processing"
+)
+
+// workqueue wrapper, keeping track of the items currently queued, waiting (that is, added with delay, e.g. rate limited), or being processed;
+// the underlying workqueue does not expose its items
+type trackingQueue struct {
+	workqueue.RateLimitingInterface
+	mutex sync.Mutex
+	items map[any]*trackedQueueItem
+}
+
+type trackedQueueItem struct {
+	state string
+	since time.Time
+	// state to be assumed when processing is done (if the item was re-added while being processed)
+	nextState string
+}
+
+func newTrackingQueue(queue workqueue.RateLimitingInterface) *trackingQueue {
+	return &trackingQueue{
+		RateLimitingInterface: queue,
+		items:                 make(map[any]*trackedQueueItem),
+	}
+}
+
+func (q *trackingQueue) track(item any, state string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	trackedItem, ok := q.items[item]
+	switch {
+	case !ok:
+		q.items[item] = &trackedQueueItem{state: state, since: time.Now()}
+	case trackedItem.state == queueItemStateProcessing:
+		if trackedItem.nextState != queueItemStateQueued {
+			trackedItem.nextState = state
+		}
+	case trackedItem.state == queueItemStateWaiting && state == queueItemStateQueued:
+		trackedItem.state = state
+		trackedItem.since = time.Now()
+	}
+}
+
+func (q *trackingQueue) Add(item any) {
+	q.track(item, queueItemStateQueued)
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q *trackingQueue) AddAfter(item any, duration time.Duration) {
+	q.track(item, queueItemStateWaiting)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+func (q *trackingQueue) AddRateLimited(item any) {
+	q.track(item, queueItemStateWaiting)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+func (q *trackingQueue) Get() (any, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	if !shutdown {
+		q.mutex.Lock()
+		q.items[item] = &trackedQueueItem{state: queueItemStateProcessing, since: time.Now()}
+		q.mutex.Unlock()
+	}
+	return item, shutdown
+}
+
+func (q *trackingQueue) Done(item any) {
+	q.mutex.Lock()
+	if trackedItem, ok := q.items[item]; ok && trackedItem.state == queueItemStateProcessing {
+		if trackedItem.nextState == "" {
+			delete(q.items, item)
+		} else {
+			q.items[item] = &trackedQueueItem{state: trackedItem.nextState, since: time.Now()}
+		}
+	}
+	q.mutex.Unlock()
+	q.RateLimitingInterface.Done(item)
+}
+
+// outcome of the last reconciliation of a clustersecret
+type reconcileInfo struct {
+	time     time.Time
+	duration time.Duration
+	err      error
+}
+
+type DebugInfo struct {
+	Workqueue      DebugWorkqueueInfo       `json:"workqueue"`
+	ClusterSecrets []DebugClusterSecretInfo `json:"clusterSecrets"`
+}
+
+type DebugWorkqueueInfo struct {
+	Length int                      `json:"length"`
+	Items  []DebugWorkqueueItemInfo `json:"items"`
+}
+
+type DebugWorkqueueItemInfo struct {
+	Type     string    `json:"type"`
+	Name     string    `json:"name"`
+	State    string    `json:"state"`
+	Since    time.Time `json:"since"`
+	Requeues int       `json:"requeues"`
+}
+
+type DebugClusterSecretInfo struct {
+	Name                  string     `json:"name"`
+	State                 string     `json:"state,omitempty"`
+	LastReconcileTime     *time.Time `json:"lastReconcileTime,omitempty"`
+	LastReconcileDuration string     `json:"lastReconcileDuration,omitempty"`
+	LastReconcileError    string     `json:"lastReconcileError,omitempty"`
+	SelectedNamespaces    []string   `json:"selectedNamespaces"`
+}
+
+func (c *Controller) recordReconcile(clusterSecretName string, start time.Time, err error) {
+	c.reconcileInfosMutex.Lock()
+	defer c.reconcileInfosMutex.Unlock()
+	if _, getErr := c.clusterSecretLister.Get(clusterSecretName); apierrors.IsNotFound(getErr) {
+		delete(c.reconcileInfos, clusterSecretName)
+		return
+	}
+	c.reconcileInfos[clusterSecretName] = &reconcileInfo{time: start, duration: time.Since(start), err: err}
+}
+
+// Return a snapshot of the controller's internal state (workqueue items, last reconciliation of every clustersecret)
+func (c *Controller) GetDebugInfo() (*DebugInfo, error) {
+	info := &DebugInfo{
+		Workqueue: DebugWorkqueueInfo{
+			Length: c.workqueue.Len(),
+			Items:  []DebugWorkqueueItemInfo{},
+		},
+		ClusterSecrets: []DebugClusterSecretInfo{},
+	}
+
+	c.workqueue.mutex.Lock()
+	for obj, trackedItem := range c.workqueue.items {
+		item, ok := obj.(workqueueItem)
+		if !ok {
+			panic("this cannot happen")
+		}
+		info.Workqueue.Items = append(info.Workqueue.Items, DebugWorkqueueItemInfo{
+			Type:     item.kind(),
+			Name:     item.name,
+			State:    trackedItem.state,
+			Since:    trackedItem.since,
+			Requeues: c.workqueue.NumRequeues(item),
+		})
+	}
+	c.workqueue.mutex.Unlock()
+	sort.Slice(info.Workqueue.Items, func(i, j int) bool {
+		if info.Workqueue.Items[i].Type != info.Workqueue.Items[j].Type {
+			return info.Workqueue.Items[i].Type < info.Workqueue.Items[j].Type
+		}
+		return info.Workqueue.Items[i].Name < info.Workqueue.Items[j].Name
+	})
+
+	clusterSecrets, err := c.clusterSecretLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(clusterSecrets, func(i, j int) bool { return clusterSecrets[i].Name < clusterSecrets[j].Name })
+	for _, clusterSecret := range clusterSecrets {
+		clusterSecretInfo := DebugClusterSecretInfo{
+			Name:               clusterSecret.Name,
+			State:              clusterSecret.Status.State,
+			SelectedNamespaces: []string{},
+		}
+		c.reconcileInfosMutex.Lock()
+		if reconcileInfo, ok := c.reconcileInfos[clusterSecret.Name]; ok {
+			reconcileTime := reconcileInfo.time
+			clusterSecretInfo.LastReconcileTime = &reconcileTime
+			clusterSecretInfo.LastReconcileDuration = reconcileInfo.duration.String()
+			if reconcileInfo.err != nil {
+				clusterSecretInfo.LastReconcileError = reconcileInfo.err.Error()
+			}
+		}
+		c.reconcileInfosMutex.Unlock()
+		if clusterSecret.DeletionTimestamp.IsZero() {
+			namespaces, err := c.getSelectedNamespaces(clusterSecret)
+			if err != nil {
+				return nil, err
+			}
+			for _, namespace := range namespaces {
+				clusterSecretInfo.SelectedNamespaces = append(clusterSecretInfo.SelectedNamespaces, namespace.Name)
+			}
+			sort.Strings(clusterSecretInfo.SelectedNamespaces)
+		}
+		info.ClusterSecrets = append(info.ClusterSecrets, clusterSecretInfo)
+	}
+
+	return info, nil
+}
+
+// Return http handler serving the controller's internal state as JSON
+func (c *Controller) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, err := c.GetDebugInfo()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(info)
+	})
+}
@@ -0,0 +1,74 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sap/clustersecret-operator/test"
+)
+
+// test: debug info
+func TestDebugInfo(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/1")
+
+	env.AddObjectsFromFiles(
+		"namespace.yaml",
+		"clustersecret.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	item := workqueueItem{key: workqueueItemKeyClusterSecret, name: "my-secret"}
+	c.workqueue.Add(item)
+	assertDebugQueueItemState(t, c, item, queueItemStateQueued)
+	obj, _ := c.workqueue.Get()
+	assertDebugQueueItemState(t, c, item, queueItemStateProcessing)
+	c.workqueue.AddRateLimited(obj)
+	c.recordReconcile(item.name, time.Now(), fmt.Errorf("some error"))
+	c.workqueue.Done(obj)
+	assertDebugQueueItemState(t, c, item, queueItemStateWaiting)
+
+	info, err := c.GetDebugInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Workqueue.Items[0].Requeues != 1 {
+		t.Errorf("unexpected requeue count; expected: 1, actual: %d", info.Workqueue.Items[0].Requeues)
+	}
+	if len(info.ClusterSecrets) != 1 {
+		t.Fatalf("unexpected number of clustersecrets; expected: 1, actual: %d", len(info.ClusterSecrets))
+	}
+	clusterSecretInfo := info.ClusterSecrets[0]
+	if clusterSecretInfo.Name != "my-secret" || clusterSecretInfo.LastReconcileTime == nil || clusterSecretInfo.LastReconcileError != "some error" {
+		t.Errorf("unexpected clustersecret info: %+v", clusterSecretInfo)
+	}
+	if !reflect.DeepEqual(clusterSecretInfo.SelectedNamespaces, []string{"my-namespace"}) {
+		t.Errorf("unexpected selected namespaces; expected: %v, actual: %v", []string{"my-namespace"}, clusterSecretInfo.SelectedNamespaces)
+	}
+}
+
+func assertDebugQueueItemState(t *testing.T, c *Controller, item workqueueItem, state string) {
+	info, err := c.GetDebugInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Workqueue.Items) != 1 {
+		t.Fatalf("unexpected number of workqueue items; expected: 1, actual: %d", len(info.Workqueue.Items))
+	}
+	itemInfo := info.Workqueue.Items[0]
+	if itemInfo.Type != item.kind() || itemInfo.Name != item.name || itemInfo.State != state {
+		t.Errorf("unexpected workqueue item; expected: %s/%s (%s), actual: %s/%s (%s)", item.kind(), item.name, state, itemInfo.Type, itemInfo.Name, itemInfo.State)
+	}
+}
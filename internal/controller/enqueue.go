@@ -7,9 +7,12 @@ package controller
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	"github.com/sap/clustersecret-operator/internal/common"
+
 	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 )
 
@@ -19,7 +22,7 @@ func (c *Controller) enqueueNamespace(eventType string, obj interface{}) {
 	if !ok {
 		panic("this cannot happen")
 	}
-	klog.V(2).Infof("enqueuing namespace %s (%s)", namespace.Name, eventType)
+	klog.V(2).InfoS("enqueuing namespace", "namespace", namespace.Name, "event", eventType)
 	c.workqueue.Add(workqueueItem{key: workqueueItemKeyNamespace, name: namespace.Name})
 }
 
@@ -32,12 +35,74 @@ func (c *Controller) enqueueClusterSecret(eventType string, obj interface{}) {
 			// that is now really strange but we don't know if it's safe to panic here; so we just silently return, i.e. ignore the object
 			return
 		}
-		klog.V(2).Infof("recovered deleted object %s from tombstone", tombstone.Key)
+		klog.V(2).InfoS("recovered deleted object from tombstone", "key", tombstone.Key)
 		clusterSecret, ok = tombstone.Obj.(*corev1alpha1.ClusterSecret)
 		if !ok {
 			panic("this cannot happen")
 		}
 	}
-	klog.V(2).Infof("enqueuing clustersecret %s (%s)", clusterSecret.Name, eventType)
+	klog.V(2).InfoS("enqueuing clustersecret", "clustersecret", clusterSecret.Name, "event", eventType)
 	c.workqueue.Add(workqueueItem{key: workqueueItemKeyClusterSecret, name: clusterSecret.Name})
 }
+
+func (c *Controller) enqueueClusterSecretsForSecret(eventType string, obj interface{}) {
+	// note: secrets are watched by a metadata informer, so only their metadata is available
+	secret, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		// try to recover from tombstone (can only happen in case of delete events, see https://pkg.go.dev/k8s.io/client-go/tools/cache#ResourceEventHandler)
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			// that is now really strange but we don't know if it's safe to panic here; so we just silently return, i.e. ignore the object
+			return
+		}
+		klog.V(2).InfoS("recovered deleted object from tombstone", "key", tombstone.Key)
+		secret, ok = tombstone.Obj.(*metav1.PartialObjectMetadata)
+		if !ok {
+			panic("this cannot happen")
+		}
+	}
+	// enqueue all clustersecrets using the secret as source secret, or reporting the secret as conflicting (either explicitly,
+	// or potentially, because their list of conflicting namespaces is truncated)
+	for _, index := range []struct{ name, key string }{
+		{indexSourceSecret, secretIndexKey(secret.Namespace, secret.Name)},
+		{indexConflictingSecret, secretIndexKey(secret.Namespace, secret.Name)},
+		{indexConflictingSecret, secretIndexKey(anyNamespace, secret.Name)},
+	} {
+		objs, err := c.clusterSecretInformer.GetIndexer().ByIndex(index.name, index.key)
+		if err != nil {
+			panic("this cannot happen")
+		}
+		for _, obj := range objs {
+			clusterSecret, ok := obj.(*corev1alpha1.ClusterSecret)
+			if !ok {
+				panic("this cannot happen")
+			}
+			klog.V(2).InfoS("enqueuing clustersecret", "clustersecret", clusterSecret.Name, "event", eventType, "index", index.name, "namespace", secret.Namespace, "secret", secret.Name)
+			c.workqueue.Add(workqueueItem{key: workqueueItemKeyClusterSecret, name: clusterSecret.Name})
+		}
+	}
+}
+
+func (c *Controller) enqueueClusterSecretForManagedSecret(eventType string, obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		// try to recover from tombstone (can only happen in case of delete events, see https://pkg.go.dev/k8s.io/client-go/tools/cache#ResourceEventHandler)
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			// that is now really strange but we don't know if it's safe to panic here; so we just silently return, i.e. ignore the object
+			return
+		}
+		klog.V(2).InfoS("recovered deleted object from tombstone", "key", tombstone.Key)
+		secret, ok = tombstone.Obj.(*corev1.Secret)
+		if !ok {
+			panic("this cannot happen")
+		}
+	}
+	// enqueue the clustersecret managing the secret (if any), such that it can repair the secret (if necessary)
+	clusterSecretName := secret.Labels[common.LabelKeyName]
+	if clusterSecretName == "" {
+		return
+	}
+	klog.V(2).InfoS("enqueuing clustersecret", "clustersecret", clusterSecretName, "event", eventType, "namespace", secret.Namespace, "secret", secret.Name)
+	c.workqueue.Add(workqueueItem{key: workqueueItemKeyClusterSecret, name: clusterSecretName})
+}
@@ -0,0 +1,93 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// EventVerbosity controls which (normal) events are emitted by the controller; warning events are always emitted
+type EventVerbosity string
+
+const (
+	// emit warning events only
+	EventVerbosityWarning EventVerbosity = "warning"
+	// emit one summary event per reconciliation of a clustersecret (if something was changed), in addition to warnings
+	EventVerbositySummary EventVerbosity = "summary"
+	// emit events on the individual managed secrets, and on namespaces triggering reconciliations, in addition to summaries
+	EventVerbosityDetailed EventVerbosity = "detailed"
+)
+
+var eventVerbosityRanks = map[EventVerbosity]int{
+	EventVerbosityWarning:  0,
+	EventVerbositySummary:  1,
+	EventVerbosityDetailed: 2,
+}
+
+// Parse event verbosity (one of warning, summary, detailed)
+func ParseEventVerbosity(s string) (EventVerbosity, error) {
+	verbosity := EventVerbosity(s)
+	if _, ok := eventVerbosityRanks[verbosity]; !ok {
+		return "", fmt.Errorf("invalid event verbosity %s (must be one of %s, %s, %s)", s, EventVerbosityWarning, EventVerbositySummary, EventVerbosityDetailed)
+	}
+	return verbosity, nil
+}
+
+// numbers of secret operations performed during a reconciliation of a clustersecret
+type reconcileSummary struct {
+	created   int
+	updated   int
+	recreated int
+	deleted   int
+	orphaned  int
+	failed    int
+}
+
+func (s *reconcileSummary) add(action string, err error) {
+	if err != nil {
+		s.failed++
+		return
+	}
+	switch action {
+	case secretActionCreated:
+		s.created++
+	case secretActionUpdated:
+		s.updated++
+	case secretActionRecreated:
+		s.recreated++
+	case secretActionDeleted:
+		s.deleted++
+	case secretActionOrphaned:
+		s.orphaned++
+	}
+}
+
+func (s *reconcileSummary) changed() bool {
+	return s.created+s.updated+s.recreated+s.deleted+s.orphaned > 0
+}
+
+func (s *reconcileSummary) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d recreated, %d deleted, %d orphaned, %d failed", s.created, s.updated, s.recreated, s.deleted, s.orphaned, s.failed)
+}
+
+// emit normal event, if the configured event verbosity includes the given verbosity
+func (c *Controller) normalEventf(verbosity EventVerbosity, object runtime.Object, reason string, messageFmt string, args ...interface{}) {
+	if eventVerbosityRanks[c.eventVerbosity] < eventVerbosityRanks[verbosity] {
+		return
+	}
+	c.eventRecorder.Eventf(object, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// emit warning event, if the configured event verbosity includes the given verbosity
+func (c *Controller) warningEventf(verbosity EventVerbosity, object runtime.Object, reason string, messageFmt string, args ...interface{}) {
+	if eventVerbosityRanks[c.eventVerbosity] < eventVerbosityRanks[verbosity] {
+		return
+	}
+	c.eventRecorder.Eventf(object, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
@@ -0,0 +1,69 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Check whether the controller is ready; a controller which is not leading (i.e. which was not started) is considered
+// ready (standby); a leading controller is ready once its informer caches are synced
+func (c *Controller) CheckReady() error {
+	if c.leading.Load() && !c.informersSynced.Load() {
+		return fmt.Errorf("informer caches not synced")
+	}
+	return nil
+}
+
+// Check whether the controller is alive; that is, whether some worker made progress (picked or completed an item,
+// or completed a secret operation) within the given time window, or the workqueue is empty
+func (c *Controller) CheckLive(window time.Duration) error {
+	if !c.leading.Load() || c.workqueue.Len() == 0 {
+		return nil
+	}
+	if since := time.Since(time.Unix(0, c.lastProgress.Load())); since > window {
+		return fmt.Errorf("no worker progress within %s (last progress %s ago, %d items queued)", window, since.Round(time.Second), c.workqueue.Len())
+	}
+	return nil
+}
+
+// Return whether the controller is leading (i.e. whether it was started)
+func (c *Controller) IsLeading() bool {
+	return c.leading.Load()
+}
+
+// Return http handler serving the readiness check; the response also reports the leadership status
+func (c *Controller) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leadership := "standby"
+		if c.IsLeading() {
+			leadership = "leading"
+		}
+		if err := c.CheckReady(); err != nil {
+			http.Error(w, fmt.Sprintf("not ready (%s): %s", leadership, err), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "ok (%s)\n", leadership)
+	})
+}
+
+// Return http handler serving the liveness check, failing if no worker made progress within the given time window
+// while the workqueue is not empty
+func (c *Controller) HealthzHandler(window time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := c.CheckLive(window); err != nil {
+			http.Error(w, fmt.Sprintf("not alive: %s", err), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+func (c *Controller) recordProgress() {
+	c.lastProgress.Store(time.Now().UnixNano())
+}
@@ -0,0 +1,68 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sap/clustersecret-operator/test"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+)
+
+// test: readiness and liveness checks
+func TestHealth(t *testing.T) {
+	env := test.NewEnvironment()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	defer cancel()
+
+	// standby controllers are ready and alive
+	if err := c.CheckReady(); err != nil {
+		t.Errorf("standby controller not ready: %s", err)
+	}
+	c.workqueue.Add(workqueueItem{key: workqueueItemKeyClusterSecret, name: "my-secret"})
+	if err := c.CheckLive(0); err != nil {
+		t.Errorf("standby controller not alive: %s", err)
+	}
+
+	// leading controllers are ready once the informers are synced
+	c.leading.Store(true)
+	c.recordProgress()
+	if err := c.CheckReady(); err == nil {
+		t.Errorf("leading controller ready without synced informers")
+	}
+	c.startInformers()
+	if err := c.CheckReady(); err != nil {
+		t.Errorf("leading controller not ready: %s", err)
+	}
+
+	// leading controllers are alive as long as workers make progress, or the queue is empty
+	if err := c.CheckLive(time.Minute); err != nil {
+		t.Errorf("leading controller not alive: %s", err)
+	}
+	c.lastProgress.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	if err := c.CheckLive(time.Minute); err == nil {
+		t.Errorf("leading controller alive without progress")
+	}
+	// completing a single secret operation (while the item is still being reconciled) counts as progress
+	old := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-secret", ResourceVersion: "1"}}
+	_, _, _ = c.applySecretOperation(ctx, secretKey{namespace: "my-namespace", name: "my-secret"}, &secretOperation{old: old}, corev1alpha1.DeletionPolicyDelete)
+	if err := c.CheckLive(time.Minute); err != nil {
+		t.Errorf("leading controller not alive after secret operation: %s", err)
+	}
+	obj, _ := c.workqueue.Get()
+	c.workqueue.Done(obj)
+	if err := c.CheckLive(time.Minute); err != nil {
+		t.Errorf("leading controller with empty queue not alive: %s", err)
+	}
+}
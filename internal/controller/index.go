@@ -0,0 +1,103 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sap/clustersecret-operator/internal/common"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+)
+
+const (
+	indexSourceSecret      = "sourceSecret"
+	indexConflictingSecret = "conflictingSecret"
+	indexNamespaceLabelKey = "namespaceLabelKey"
+	indexOwner             = "owner"
+)
+
+const (
+	// index key of clustersecrets whose namespace selector does not require any label key (note: label keys cannot be empty)
+	namespaceLabelKeyNone = ""
+	// namespace part of the index key of clustersecrets whose list of conflicting namespaces is truncated (note: not a valid namespace name)
+	anyNamespace = "*"
+)
+
+// index clustersecrets by the namespace/name key of their source secret (if any)
+func indexClusterSecretBySourceSecret(obj interface{}) ([]string, error) {
+	clusterSecret, ok := obj.(*corev1alpha1.ClusterSecret)
+	if !ok {
+		panic("this cannot happen")
+	}
+	if clusterSecret.Spec.SourceRef == nil {
+		return nil, nil
+	}
+	return []string{secretIndexKey(clusterSecret.Spec.SourceRef.Namespace, clusterSecret.Spec.SourceRef.Name)}, nil
+}
+
+// index clustersecrets by the namespace/name keys of the conflicting (unmanaged) secrets reported in their status; if the reported list
+// is truncated, the clustersecret is additionally indexed by the key anyNamespace/name, matching conflicting secrets in any namespace
+func indexClusterSecretByConflictingSecret(obj interface{}) ([]string, error) {
+	clusterSecret, ok := obj.(*corev1alpha1.ClusterSecret)
+	if !ok {
+		panic("this cannot happen")
+	}
+	var keys []string
+	for _, namespace := range clusterSecret.Status.ConflictingNamespaces {
+		keys = append(keys, secretIndexKey(namespace, common.GetSecretName(clusterSecret)))
+	}
+	if int(clusterSecret.Status.ConflictingNamespaceCount) > len(clusterSecret.Status.ConflictingNamespaces) {
+		keys = append(keys, secretIndexKey(anyNamespace, common.GetSecretName(clusterSecret)))
+	}
+	return keys, nil
+}
+
+// index clustersecrets by one of the label keys which a namespace must have in order to be selected by the namespace selector;
+// clustersecrets whose selector does not require any label key (e.g. because it is empty) are indexed by namespaceLabelKeyNone;
+// note: one key is sufficient (since namespaces without that label key cannot be selected); the lexicographically smallest key is used
+func indexClusterSecretByNamespaceLabelKey(obj interface{}) ([]string, error) {
+	clusterSecret, ok := obj.(*corev1alpha1.ClusterSecret)
+	if !ok {
+		panic("this cannot happen")
+	}
+	var keys []string
+	if selector := clusterSecret.Spec.NamespaceSelector; selector != nil {
+		for key := range selector.MatchLabels {
+			keys = append(keys, key)
+		}
+		for _, requirement := range selector.MatchExpressions {
+			if requirement.Operator == metav1.LabelSelectorOpIn || requirement.Operator == metav1.LabelSelectorOpExists {
+				keys = append(keys, requirement.Key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return []string{namespaceLabelKeyNone}, nil
+	}
+	sort.Strings(keys)
+	return keys[:1], nil
+}
+
+// index (managed) secrets by the name of the owning clustersecret (i.e. by the value of the name label)
+func indexSecretByOwner(obj interface{}) ([]string, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		panic("this cannot happen")
+	}
+	if owner := secret.Labels[common.LabelKeyName]; owner != "" {
+		return []string{owner}, nil
+	}
+	return nil, nil
+}
+
+func secretIndexKey(namespace string, name string) string {
+	// note: this is the same key format as used by cache.MetaNamespaceKeyFunc
+	return namespace + "/" + name
+}
@@ -0,0 +1,161 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/sap/clustersecret-operator/test"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+)
+
+// test: index of clustersecrets by the label keys required by their namespace selector
+func TestIndexClusterSecretByNamespaceLabelKey(t *testing.T) {
+	tests := []struct {
+		selector *metav1.LabelSelector
+		keys     []string
+	}{
+		{nil, []string{namespaceLabelKeyNone}},
+		{&metav1.LabelSelector{}, []string{namespaceLabelKeyNone}},
+		{&metav1.LabelSelector{MatchLabels: map[string]string{"b": "x", "a": "y"}}, []string{"a"}},
+		{&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: metav1.LabelSelectorOpExists}}}, []string{"a"}},
+		{&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: metav1.LabelSelectorOpIn, Values: []string{"x"}}}}, []string{"a"}},
+		{&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"x"}}}}, []string{namespaceLabelKeyNone}},
+		{&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: metav1.LabelSelectorOpDoesNotExist}}}, []string{namespaceLabelKeyNone}},
+		{&metav1.LabelSelector{
+			MatchLabels:      map[string]string{"c": "x"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: metav1.LabelSelectorOpDoesNotExist}, {Key: "b", Operator: metav1.LabelSelectorOpExists}},
+		}, []string{"b"}},
+	}
+	for _, test := range tests {
+		keys, err := indexClusterSecretByNamespaceLabelKey(&corev1alpha1.ClusterSecret{Spec: corev1alpha1.ClusterSecretSpec{NamespaceSelector: test.selector}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("selector %v: expected keys %v, got %v", test.selector, test.keys, keys)
+		}
+	}
+}
+
+// test: index of clustersecrets by the conflicting secrets reported in their status
+func TestIndexClusterSecretByConflictingSecret(t *testing.T) {
+	tests := []struct {
+		count      int32
+		namespaces []string
+		keys       []string
+	}{
+		{0, nil, nil},
+		{2, []string{"a", "b"}, []string{"a/my-secret", "b/my-secret"}},
+		{3, []string{"a", "b"}, []string{"a/my-secret", "b/my-secret", anyNamespace + "/my-secret"}},
+	}
+	for _, test := range tests {
+		clusterSecret := &corev1alpha1.ClusterSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-secret"},
+			Status:     corev1alpha1.ClusterSecretStatus{ConflictingNamespaceCount: test.count, ConflictingNamespaces: test.namespaces},
+		}
+		keys, err := indexClusterSecretByConflictingSecret(clusterSecret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("conflicting namespaces %d %v: expected keys %v, got %v", test.count, test.namespaces, test.keys, keys)
+		}
+	}
+}
+
+// benchmark: finding the clustersecrets selecting a namespace, in a cluster with many namespaces and clustersecrets;
+// compares evaluating the selectors of all clustersecrets (as done by earlier versions of the controller) with the indexed lookup;
+// run with: go test ./internal/controller -run '^$' -bench FindClusterSecretsForNamespace
+func BenchmarkFindClusterSecretsForNamespace(b *testing.B) {
+	const numNamespaces = 2000
+	const numClusterSecrets = 500
+	const numLabelsPerNamespace = 5
+
+	env := test.NewEnvironment()
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	// note: objects are created after the informers were started, in batches, since the watchers of the test environment have a limited capacity
+	const batchSize = 50
+	must := env.Must(func(err error) { b.Fatal(err) })
+	for i := 0; i < numNamespaces; i++ {
+		namespaceLabels := map[string]string{"team": fmt.Sprintf("team-%d", i%20)}
+		for j := 0; j < numLabelsPerNamespace; j++ {
+			namespaceLabels[fmt.Sprintf("app-%d", (i+j*97)%numClusterSecrets)] = "enabled"
+		}
+		must.CreateNamespace(&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("namespace-%d", i), Labels: namespaceLabels},
+		})
+		if i%batchSize == 0 {
+			c.synchronizer.WaitUntilSynced()
+		}
+	}
+	for i := 0; i < numClusterSecrets; i++ {
+		// most clustersecrets select namespaces by an application specific label, some by team, and few select all namespaces
+		var selector *metav1.LabelSelector
+		switch {
+		case i%50 == 0:
+		case i%10 == 0:
+			selector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": fmt.Sprintf("team-%d", i%20)}}
+		default:
+			selector = &metav1.LabelSelector{MatchLabels: map[string]string{fmt.Sprintf("app-%d", i): "enabled"}}
+		}
+		must.CreateClusterSecret(&corev1alpha1.ClusterSecret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: corev1alpha1.ClusterSecretGroupVersionKind.GroupVersion().String(), Kind: corev1alpha1.ClusterSecretGroupVersionKind.Kind},
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("clustersecret-%d", i)},
+			Spec: corev1alpha1.ClusterSecretSpec{
+				NamespaceSelector: selector,
+				Template:          corev1alpha1.SecretTemplateSpec{Type: corev1.SecretTypeOpaque},
+			},
+		})
+		if i%batchSize == 0 {
+			c.synchronizer.WaitUntilSynced()
+		}
+	}
+	c.synchronizer.WaitUntilSynced()
+
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			namespace := namespaces[i%len(namespaces)]
+			clusterSecrets, err := c.clusterSecretLister.List(labels.Everything())
+			if err != nil {
+				b.Fatal(err)
+			}
+			var selectingClusterSecrets []*corev1alpha1.ClusterSecret
+			for _, clusterSecret := range clusterSecrets {
+				if namespaceMatchesClusterSecret(namespace, clusterSecret) {
+					selectingClusterSecrets = append(selectingClusterSecrets, clusterSecret)
+				}
+			}
+		}
+	})
+
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			namespace := namespaces[i%len(namespaces)]
+			if _, err := c.getClusterSecretsSelectingNamespace(namespace); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,172 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kuberuntime "k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/metadata"
+	metadatafake "k8s.io/client-go/metadata/fake"
+
+	"github.com/sap/clustersecret-operator/internal/common"
+
+	corefake "github.com/sap/clustersecret-operator/pkg/client/clientset/versioned/fake"
+)
+
+// benchmark: memory used by the informers in a cluster with many (large) unmanaged secrets (such as helm release secrets);
+// compares plain (unfiltered) informers, as used by earlier versions of the controller, with the informers of the controller;
+// reports the peak heap growth while the informers perform their initial list (peak-MiB/op), and the heap growth retained
+// by the informer caches after the initial sync (MiB/op);
+// note: the fake clients copy all stored objects before applying label selectors, whereas the api server filters (and strips data for metadata
+// requests) before sending anything; therefore, in the controller variant, the fake kubernetes client holds the managed secrets only, and the fake
+// metadata client holds the metadata of all secrets;
+// run with: go test ./internal/controller -run '^$' -bench InformerMemory
+func BenchmarkInformerMemory(b *testing.B) {
+	const numNamespaces = 10
+	const numUnmanagedSecrets = 1000
+	const numManagedSecrets = 100
+	const dataSize = 64 * 1024
+
+	var namespaces []*corev1.Namespace
+	for i := 0; i < numNamespaces; i++ {
+		namespaces = append(namespaces, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("namespace-%d", i)}})
+	}
+	var unmanagedSecrets []*corev1.Secret
+	for i := 0; i < numUnmanagedSecrets; i++ {
+		unmanagedSecrets = append(unmanagedSecrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: fmt.Sprintf("namespace-%d", i%numNamespaces), Name: fmt.Sprintf("unmanaged-%d", i)},
+			Data:       map[string][]byte{"release": bytes.Repeat([]byte{'x'}, dataSize)},
+		})
+	}
+	var managedSecrets []*corev1.Secret
+	for i := 0; i < numManagedSecrets; i++ {
+		managedSecrets = append(managedSecrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: fmt.Sprintf("namespace-%d", i%numNamespaces),
+				Name:      fmt.Sprintf("managed-%d", i),
+				Labels:    map[string]string{common.LabelKeyName: fmt.Sprintf("managed-%d", i)},
+			},
+			Data: map[string][]byte{"key": bytes.Repeat([]byte{'x'}, 64)},
+		})
+	}
+
+	newKubeClient := func(withUnmanagedSecrets bool) kubernetes.Interface {
+		var objects []kuberuntime.Object
+		for _, namespace := range namespaces {
+			objects = append(objects, namespace)
+		}
+		if withUnmanagedSecrets {
+			for _, secret := range unmanagedSecrets {
+				objects = append(objects, secret)
+			}
+		}
+		for _, secret := range managedSecrets {
+			objects = append(objects, secret)
+		}
+		return kubefake.NewSimpleClientset(objects...)
+	}
+
+	newMetadataClient := func() metadata.Interface {
+		scheme := kuberuntime.NewScheme()
+		metav1.AddMetaToScheme(scheme)
+		var objects []kuberuntime.Object
+		for _, secret := range append(append([]*corev1.Secret{}, unmanagedSecrets...), managedSecrets...) {
+			objects = append(objects, &metav1.PartialObjectMetadata{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+				ObjectMeta: *secret.ObjectMeta.DeepCopy(),
+			})
+		}
+		return metadatafake.NewSimpleMetadataClient(scheme, objects...)
+	}
+
+	// return the current size of the live (and not yet collected) heap objects
+	heapObjectBytes := func() uint64 {
+		samples := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+		metrics.Read(samples)
+		return samples[0].Value.Uint64()
+	}
+
+	// measure the heap growth caused by starting the informers returned by the given function (averaged over all iterations);
+	// the clients are created before measuring, since their object trackers play the role of the api server
+	measure := func(b *testing.B, newClients func() (kubernetes.Interface, metadata.Interface), start func(ctx context.Context, kubeclient kubernetes.Interface, metadataclient metadata.Interface) any) {
+		var totalPeak uint64
+		var totalRetained uint64
+		for i := 0; i < b.N; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			kubeclient, metadataclient := newClients()
+			runtime.GC()
+			before := heapObjectBytes()
+			peak := before
+			done := make(chan struct{})
+			sampled := make(chan struct{})
+			go func() {
+				defer close(sampled)
+				ticker := time.NewTicker(100 * time.Microsecond)
+				defer ticker.Stop()
+				for {
+					if current := heapObjectBytes(); current > peak {
+						peak = current
+					}
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+			informers := start(ctx, kubeclient, metadataclient)
+			close(done)
+			<-sampled
+			runtime.GC()
+			after := heapObjectBytes()
+			runtime.KeepAlive(informers)
+			runtime.KeepAlive(kubeclient)
+			runtime.KeepAlive(metadataclient)
+			totalPeak += peak - before
+			if after > before {
+				totalRetained += after - before
+			}
+			cancel()
+		}
+		b.ReportMetric(float64(totalPeak)/float64(b.N)/(1024*1024), "peak-MiB/op")
+		b.ReportMetric(float64(totalRetained)/float64(b.N)/(1024*1024), "MiB/op")
+	}
+
+	b.Run("unfiltered", func(b *testing.B) {
+		measure(b, func() (kubernetes.Interface, metadata.Interface) {
+			return newKubeClient(true), nil
+		}, func(ctx context.Context, kubeclient kubernetes.Interface, metadataclient metadata.Interface) any {
+			factory := kubeinformers.NewSharedInformerFactory(kubeclient, 300*time.Second)
+			factory.Core().V1().Namespaces().Informer()
+			factory.Core().V1().Secrets().Informer()
+			factory.Start(ctx.Done())
+			factory.WaitForCacheSync(ctx.Done())
+			return factory
+		})
+	})
+
+	b.Run("controller", func(b *testing.B) {
+		measure(b, func() (kubernetes.Interface, metadata.Interface) {
+			return newKubeClient(false), newMetadataClient()
+		}, func(ctx context.Context, kubeclient kubernetes.Interface, metadataclient metadata.Interface) any {
+			c := NewController(ctx, kubeclient, metadataclient, corefake.NewSimpleClientset(), nil, Options{})
+			c.startInformers()
+			return c
+		})
+	})
+}
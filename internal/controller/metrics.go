@@ -0,0 +1,73 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/klog/v2"
+
+	"github.com/sap/clustersecret-operator/internal/common"
+)
+
+var managedSecretsDesc = prometheus.NewDesc(
+	"clustersecret_operator_managed_secrets",
+	"Number of secrets managed per clustersecret",
+	[]string{"clustersecret"},
+	nil,
+)
+
+var driftedNamespacesDesc = prometheus.NewDesc(
+	"clustersecret_operator_drifted_namespaces",
+	"Number of namespaces containing a modified (drifted) secret per clustersecret, as detected by the last audit",
+	[]string{"clustersecret"},
+	nil,
+)
+
+// collector exposing controller state, computed from the informer caches at scrape time
+type metricsCollector struct {
+	controller *Controller
+}
+
+// Return a prometheus collector exposing the number of managed and drifted secrets per clustersecret
+func (c *Controller) MetricsCollector() prometheus.Collector {
+	return &metricsCollector{controller: c}
+}
+
+func (m *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedSecretsDesc
+	ch <- driftedNamespacesDesc
+}
+
+func (m *metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := make(map[string]int)
+	clusterSecrets, err := m.controller.clusterSecretLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "error listing clustersecrets for metrics collection")
+		return
+	}
+	for _, clusterSecret := range clusterSecrets {
+		counts[clusterSecret.Name] = 0
+		ch <- prometheus.MustNewConstMetric(driftedNamespacesDesc, prometheus.GaugeValue, float64(clusterSecret.Status.DriftedNamespaces), clusterSecret.Name)
+	}
+	requirement, err := labels.NewRequirement(common.LabelKeyName, selection.Exists, nil)
+	if err != nil {
+		panic("this cannot happen")
+	}
+	secrets, err := m.controller.secretLister.List(labels.NewSelector().Add(*requirement))
+	if err != nil {
+		klog.ErrorS(err, "error listing secrets for metrics collection")
+		return
+	}
+	for _, secret := range secrets {
+		counts[secret.Labels[common.LabelKeyName]]++
+	}
+	for name, count := range counts {
+		ch <- prometheus.MustNewConstMetric(managedSecretsDesc, prometheus.GaugeValue, float64(count), name)
+	}
+}
@@ -8,8 +8,12 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	multierror "github.com/hashicorp/go-multierror"
+	"go.opentelemetry.io/otel/trace"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -17,7 +21,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels" // could also be aliased 'kubeclients' but we keep it as 'kubernetes' since most people do
 	"k8s.io/klog/v2"
 
-	conversionutils "github.com/sap/clustersecret-operator/internal/utils/conversion"
+	"github.com/sap/clustersecret-operator/internal/common"
+	"github.com/sap/clustersecret-operator/internal/tracing"
 
 	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 )
@@ -27,20 +32,42 @@ type secretKey struct {
 	name      string
 }
 
+// outcome of the reconciliation of a single secret
+type secretResult struct {
+	action  string
+	message string
+	err     error
+}
+
 type secretOperation struct {
-	old *corev1.Secret
-	new *corev1.Secret
+	old      *corev1.Secret
+	new      *corev1.Secret
+	recreate bool
 }
 
+// actions performed on secrets (also used as event reasons)
+const (
+	secretActionCreated   = "Created"
+	secretActionUpdated   = "Updated"
+	secretActionRecreated = "Recreated"
+	secretActionDeleted   = "Deleted"
+	secretActionOrphaned  = "Orphaned"
+)
+
 const (
-	LabelKeyName            = "clustersecrets.core.cs.sap.com/name"
-	AnnotationKeyGeneration = "clustersecrets.core.cs.sap.com/generation"
+	// maximum number of failing namespaces (with error messages) listed in the clustersecret status
+	maxStatusFailures = 10
+	// maximum number of conflicting namespaces listed in the clustersecret status (and in conflict events)
+	maxStatusConflictingNamespaces = 10
+	// maximum length of condition messages in the clustersecret status
+	maxConditionMessageLength = 1024
 )
 
-func (c *Controller) reconcileNamespace(namespaceName string) error {
+func (c *Controller) reconcileNamespace(ctx context.Context, namespaceName string) error {
 	// note: due to the implementation details of the workqueue it is guaranteed that this function will not run concurrently for the same namespace
 
-	klog.V(2).Infof("reconciling namespace %s", namespaceName)
+	logger := klog.FromContext(ctx)
+	logger.V(2).Info("reconciling namespace")
 
 	// wait for caches to be synchronized
 	if c.synchronizer != nil {
@@ -53,7 +80,7 @@ func (c *Controller) reconcileNamespace(namespaceName string) error {
 		if errors.IsNotFound(err) {
 			// that is a bit strange, since this should be only triggered after namespace create/update events
 			// however it may happen if the  namespace has been deleted concurrently
-			klog.Warningf("namespace %s does not exist; skipping reconcile", namespaceName)
+			logger.Info("namespace does not exist; skipping reconcile")
 			return nil
 		} else {
 			return err
@@ -69,7 +96,7 @@ func (c *Controller) reconcileNamespace(namespaceName string) error {
 	clusterSecretNames := make(map[string]struct{})
 
 	// ... first, find all managed secrets in specified namespace
-	secretSelector, err := labels.Parse(LabelKeyName)
+	secretSelector, err := labels.Parse(common.LabelKeyName)
 	if err != nil {
 		panic("this cannot happen")
 	}
@@ -78,26 +105,25 @@ func (c *Controller) reconcileNamespace(namespaceName string) error {
 		c.eventRecorder.Event(namespace, corev1.EventTypeWarning, "Error", err.Error())
 		return err
 	}
+	// note: the owning clustersecret is identified by the label value, since the secret name may differ from the clustersecret name
 	for _, secret := range existingSecrets {
-		clusterSecretNames[secret.Name] = struct{}{}
+		clusterSecretNames[secret.Labels[common.LabelKeyName]] = struct{}{}
 	}
 
 	// ... then, find all clustersecrets selecting the specified namespace
-	clusterSecrets, err := c.clusterSecretLister.List(labels.Everything())
+	clusterSecrets, err := c.getClusterSecretsSelectingNamespace(namespace)
 	if err != nil {
 		c.eventRecorder.Event(namespace, corev1.EventTypeWarning, "Error", err.Error())
 		return err
 	}
 	for _, clusterSecret := range clusterSecrets {
-		namespaceSelector := buildNamespaceSelectorFromClusterSecret(clusterSecret)
-		if namespaceSelector.Matches(labels.Set(namespace.Labels)) {
-			clusterSecretNames[clusterSecret.Name] = struct{}{}
-		}
+		clusterSecretNames[clusterSecret.Name] = struct{}{}
 	}
 
 	// schedule a reconciliation for all these determined clustersecrets
 	for clusterSecretName := range clusterSecretNames {
-		c.eventRecorder.Eventf(namespace, corev1.EventTypeNormal, "TriggerClusterSecretReconcile", "Successfully triggered reconciliation of clustersecret %s", clusterSecretName)
+		c.normalEventf(EventVerbosityDetailed, namespace, "TriggerClusterSecretReconcile", "Successfully triggered reconciliation of clustersecret %s", clusterSecretName)
+		logger.V(2).Info("enqueuing clustersecret", "clustersecret", clusterSecretName)
 		c.workqueue.Add(workqueueItem{key: workqueueItemKeyClusterSecret, name: clusterSecretName})
 	}
 
@@ -105,10 +131,11 @@ func (c *Controller) reconcileNamespace(namespaceName string) error {
 	return nil
 }
 
-func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
+func (c *Controller) reconcileClusterSecret(ctx context.Context, clusterSecretName string) error {
 	// note: due to the implementation details of the workqueue it is guaranteed that this function will not run concurrently for the same clustersecret
 
-	klog.V(2).Infof("reconciling clustersecret %s", clusterSecretName)
+	logger := klog.FromContext(ctx)
+	logger.V(2).Info("reconciling clustersecret")
 
 	// wait for caches to be synchronized
 	if c.synchronizer != nil {
@@ -117,7 +144,7 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 
 	// fetch clustersecret (if existing)
 	// note: we cannot fetch it from the lister because the cached state might not yet reflect updates done by (very recent) previous invocations of this function
-	clusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Get(context.TODO(), clusterSecretName, metav1.GetOptions{})
+	clusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Get(ctx, clusterSecretName, metav1.GetOptions{})
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			return err
@@ -127,7 +154,7 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 
 	// set finalizer
 	if clusterSecret != nil && clusterSecret.DeletionTimestamp.IsZero() {
-		if err := c.setClusterSecretFinalizer(clusterSecret); err != nil {
+		if err := c.setClusterSecretFinalizer(ctx, clusterSecret); err != nil {
 			c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
 			return err
 		}
@@ -140,9 +167,62 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 		return err
 	}
 
+	// roll back template (if requested), and record the template as new revision (if changed)
+	if clusterSecret != nil && clusterSecret.DeletionTimestamp.IsZero() && c.revisionHistoryEnabled() {
+		if _, ok := clusterSecret.Annotations[common.AnnotationKeyRollbackTo]; ok {
+			updatedClusterSecret, err := c.rollbackClusterSecret(ctx, clusterSecret)
+			if err != nil {
+				c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
+				return err
+			}
+			clusterSecret = updatedClusterSecret
+		}
+		if err := c.recordRevision(ctx, clusterSecret); err != nil {
+			c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
+			return err
+		}
+	}
+
+	// determine secret data (if clustersecret is not deleted or in deletion); either from the template, or from the referenced source secret
+	var data map[string][]byte
+	if clusterSecret != nil && clusterSecret.DeletionTimestamp.IsZero() {
+		if sourceRef := clusterSecret.Spec.SourceRef; sourceRef == nil {
+			data = clusterSecret.Spec.Template.Data
+		} else {
+			sourceSecret, err := c.getSourceSecret(ctx, sourceRef.Namespace, sourceRef.Name)
+			if err == nil {
+				data, err = buildDataFromSourceSecret(sourceSecret, sourceRef.Keys)
+			} else if errors.IsNotFound(err) {
+				err = fmt.Errorf("source secret %s/%s not found", sourceRef.Namespace, sourceRef.Name)
+			} else {
+				c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
+				return err
+			}
+			if err != nil {
+				// source secret is missing or incomplete; existing secrets are left untouched, and no secrets will be created;
+				// there is no need to requeue, since changes of the source secret will trigger a reconciliation anyway
+				logger.Info("source secret missing", "namespace", sourceRef.Namespace, "secret", sourceRef.Name, "reason", err.Error())
+				c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "SourceMissing", err.Error())
+				distributionStatus := clusterSecret.Status.DeepCopy()
+				distributionStatus.Conditions = []corev1alpha1.ClusterSecretCondition{
+					{Type: corev1alpha1.ClusterSecretConditionTypeSynced, Status: corev1.ConditionFalse, Reason: "SourceMissing", Message: err.Error()},
+					{Type: corev1alpha1.ClusterSecretConditionTypeDegraded, Status: corev1.ConditionTrue, Reason: "SourceMissing", Message: err.Error()},
+				}
+				if err := c.updateClusterSecretDistributionStatus(ctx, clusterSecret, distributionStatus); err != nil {
+					c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
+					return err
+				}
+				if err := c.updateClusterSecretStatusWithReason(ctx, clusterSecret, corev1alpha1.StateError, "SourceMissing", err.Error()); err != nil {
+					c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
+					return err
+				}
+				return nil
+			}
+		}
+	}
+
 	// fetch all secrets managed by this clustersecret in all namespaces
-	secretSelector := labels.SelectorFromSet(map[string]string{LabelKeyName: clusterSecretName})
-	existingSecrets, err := c.secretLister.List(secretSelector)
+	existingSecrets, err := c.getManagedSecrets(clusterSecretName)
 	if err != nil {
 		if clusterSecret != nil {
 			c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
@@ -150,6 +230,12 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 		return err
 	}
 
+	// errors occurring while reconciling individual secrets are collected and reported at the end;
+	// in addition, failure messages are collected per namespace, and reported in the status (along with the selected namespaces)
+	var merr *multierror.Error
+	var matchedNamespaces []string
+	failures := make(map[string]string)
+
 	// determine set of secrets to reconcile ...
 	operations := make(map[secretKey]*secretOperation)
 	// ... first, consider all existing managed secrets
@@ -158,24 +244,53 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 		operations[key] = &secretOperation{old: secret}
 	}
 	// ... then (if clustersecret is not deleted or in deletion), consider the wanted generated secret in all selected namespaces
+	// (thereby detecting existing secrets with the target name which are not managed by this clustersecret)
+	var conflictingNamespaces []string
 	if clusterSecret != nil && clusterSecret.DeletionTimestamp.IsZero() {
-		namespaceSelector := buildNamespaceSelectorFromClusterSecret(clusterSecret)
-		matchingNamespaces, err := c.namespaceLister.List(namespaceSelector)
+		conflictPolicy := getConflictPolicy(clusterSecret)
+		selectedNamespaces, err := c.getSelectedNamespaces(clusterSecret)
 		if err != nil {
 			c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
 			return err
 		}
-		for _, namespace := range matchingNamespaces {
-			// skip if namespace is in deletion; if a secret exists in that namespace, it will be deleted through the operations entry
-			// (which is not necessary, but does not harm)
-			if !namespace.DeletionTimestamp.IsZero() {
+		for _, namespace := range selectedNamespaces {
+			matchedNamespaces = append(matchedNamespaces, namespace.Name)
+			key := secretKey{namespace.Name, common.GetSecretName(clusterSecret)}
+			secret, err := buildSecretFromClusterSecret(namespace, clusterSecret, data)
+			if err != nil {
+				// rendering failed for this namespace; an existing secret in this namespace is left untouched
+				merr = multierror.Append(merr, fmt.Errorf("error rendering secret %s/%s: %s", key.namespace, key.name, err))
+				failures[key.namespace] = fmt.Sprintf("error rendering secret: %s", err)
+				delete(operations, key)
 				continue
 			}
-			key := secretKey{namespace.Name, clusterSecret.Name}
 			if operation, ok := operations[key]; ok {
-				operation.new = buildSecretFromClusterSecret(namespace.Name, clusterSecret)
+				operation.new = secret
+			} else if existingSecret, err := c.secretMetadataLister.Namespace(key.namespace).Get(key.name); err == nil {
+				// a secret with the target name exists, but is not managed by this clustersecret; secrets managed by another clustersecret
+				// are never adopted (but treated according to the conflict policy otherwise)
+				if conflictPolicy == corev1alpha1.ConflictPolicyAdopt && existingSecret.Labels[common.LabelKeyName] == "" {
+					logger.V(2).Info("adopting secret", "namespace", key.namespace, "secret", key.name)
+					// note: only the metadata of unmanaged secrets is cached, so the complete secret has to be read from the API server
+					adoptedSecret, err := c.kubeclient.CoreV1().Secrets(key.namespace).Get(ctx, key.name, metav1.GetOptions{})
+					if err != nil {
+						c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
+						return err
+					}
+					operations[key] = &secretOperation{old: adoptedSecret, new: secret}
+					continue
+				}
+				conflictingNamespaces = append(conflictingNamespaces, key.namespace)
+				if conflictPolicy != corev1alpha1.ConflictPolicySkip {
+					err := fmt.Errorf("secret %s/%s already exists and is not managed by clustersecret %s", key.namespace, key.name, clusterSecret.Name)
+					merr = multierror.Append(merr, err)
+					failures[key.namespace] = err.Error()
+				}
+			} else if errors.IsNotFound(err) {
+				operations[key] = &secretOperation{new: secret}
 			} else {
-				operations[key] = &secretOperation{new: buildSecretFromClusterSecret(namespace.Name, clusterSecret)}
+				c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
+				return err
 			}
 		}
 		for key, operation := range operations {
@@ -183,89 +298,104 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 			if operation.old != nil && operation.new != nil {
 				operation.new.ResourceVersion = operation.old.ResourceVersion
 				// skip/remove all secrets which are already up-to-date
-				if operation.old != nil && conversionutils.Atoi(operation.old.Annotations[AnnotationKeyGeneration]) >= clusterSecret.Generation {
+				if isSecretUpToDate(operation.old, operation.new) {
 					delete(operations, key)
+					continue
 				}
+				// secrets whose type changes, or which are immutable, cannot be updated, but must be deleted and recreated
+				operation.recreate = isSecretRecreationNeeded(operation.old, operation.new)
 			}
 		}
 	}
 
-	// update status (if applicable); set to Processing or Deleting respectively (unless it's already in Error state; in that case it stays Error)
-	if clusterSecret != nil && clusterSecret.Status.State != corev1alpha1.StateError {
+	// update status (if applicable); set to Processing or Deleting respectively (unless it's already in Error state, and the generation did not change;
+	// in that case it stays Error until the next fully successful reconciliation)
+	if clusterSecret != nil && (clusterSecret.Status.State != corev1alpha1.StateError || clusterSecret.Generation > clusterSecret.Status.ObservedGeneration) {
 		if clusterSecret.DeletionTimestamp.IsZero() {
 			if clusterSecret.Generation > clusterSecret.Status.ObservedGeneration || len(operations) > 0 {
-				if err := c.updateClusterSecretStatus(clusterSecret, corev1alpha1.StateProcessing); err != nil {
+				if err := c.updateClusterSecretStatus(ctx, clusterSecret, corev1alpha1.StateProcessing, ""); err != nil {
 					c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
 					return err
 				}
 			}
 		} else {
-			if err := c.updateClusterSecretStatus(clusterSecret, corev1alpha1.StateDeleting); err != nil {
+			if err := c.updateClusterSecretStatus(ctx, clusterSecret, corev1alpha1.StateDeleting, ""); err != nil {
 				c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
 				return err
 			}
 		}
 	}
 
-	// reconcile all determined secrets (as determined in operations), and update status (if applicable) to Ready or Error, respectively
-	var merr *multierror.Error
-	for key, operation := range operations {
-		if operation.new == nil {
-			// this is a deletion
-			// note: we can assume that operation.old is not nil because of the way how operations was defined
-			klog.V(2).Infof("deleting secret %s/%s (if existing)", key.namespace, key.name)
-			err := c.kubeclient.CoreV1().Secrets(key.namespace).Delete(
-				context.TODO(),
-				key.name,
-				metav1.DeleteOptions{Preconditions: &metav1.Preconditions{ResourceVersion: &operation.old.ResourceVersion}},
-			)
-			if err != nil {
-				if !errors.IsNotFound(err) {
-					merr = multierror.Append(
-						merr, fmt.Errorf("error deleting secret %s/%s", key.namespace, key.name), err,
-					)
-				}
-			}
-			if recorder, ok := c.synchronizer.(Recorder); ok {
-				recorder.RecordDeletion(operation.old)
-			}
-		} else if operation.old == nil {
-			// this is a creation
-			// note: this can fail in particular if the secret already exists, but is not managed by us
-			klog.V(2).Infof("create secret %s/%s", key.namespace, key.name)
-			secret, err := c.kubeclient.CoreV1().Secrets(key.namespace).Create(
-				context.TODO(),
-				operation.new,
-				metav1.CreateOptions{FieldManager: ControllerName},
-			)
-			if err != nil {
-				merr = multierror.Append(
-					merr, fmt.Errorf("error creating secret %s/%s", key.namespace, key.name), err,
-				)
-			}
-			if recorder, ok := c.synchronizer.(Recorder); ok {
-				recorder.RecordCreation(secret)
-			}
-		} else {
-			// this is an update
-			klog.V(2).Infof("update secret %s/%s", key.namespace, key.name)
-			secret, err := c.kubeclient.CoreV1().Secrets(key.namespace).Update(
-				context.TODO(),
-				operation.new,
-				metav1.UpdateOptions{FieldManager: ControllerName},
-			)
-			if err != nil {
-				merr = multierror.Append(merr, fmt.Errorf("error updating secret %s/%s", key.namespace, key.name), err)
-			}
-			if recorder, ok := c.synchronizer.(Recorder); ok {
-				recorder.RecordUpdate(operation.old, secret)
-			}
+	// report conflicts (if any)
+	if clusterSecret != nil && len(conflictingNamespaces) > 0 {
+		sort.Strings(conflictingNamespaces)
+		c.eventRecorder.Eventf(clusterSecret, corev1.EventTypeWarning, "SecretConflict", "Found unmanaged secret %s in %d namespace(s): %s (conflict policy %s)", common.GetSecretName(clusterSecret), len(conflictingNamespaces), strings.Join(listConflictingNamespaces(conflictingNamespaces), ", "), getConflictPolicy(clusterSecret))
+	}
+
+	// determine what happens to secrets which are no longer wanted (either deleted or orphaned)
+	deletionPolicy := getDeletionPolicy(clusterSecret)
+
+	// reconcile all determined secrets (as determined in operations), and update status (if applicable) to Ready or Error, respectively;
+	// secrets are reconciled in parallel (with bounded concurrency); the results are collected per secret, and aggregated after all
+	// secrets were reconciled, in a deterministic order
+	// note: the number of requests per second is additionally limited by the rate limiter of the kubernetes client (that is, by its QPS and burst settings)
+	keys := make([]secretKey, 0, len(operations))
+	for key := range operations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].namespace < keys[j].namespace || keys[i].namespace == keys[j].namespace && keys[i].name < keys[j].name
+	})
+	results := make([]secretResult, len(keys))
+	semaphore := make(chan struct{}, c.secretWriteConcurrency)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		// note: once the context is done, the remaining secrets are not reconciled (but reported as failed)
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			results[i].message, results[i].err = fmt.Sprintf("error reconciling secret %s/%s", key.namespace, key.name), ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, key secretKey) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i].action, results[i].message, results[i].err = c.reconcileSecret(ctx, clusterSecretName, key, operations[key], deletionPolicy)
+		}(i, key)
+	}
+	wg.Wait()
+	var summary reconcileSummary
+	for i, key := range keys {
+		result := results[i]
+		if result.err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("%s", result.message), result.err)
+			failures[key.namespace] = result.err.Error()
+		}
+		summary.add(result.action, result.err)
+	}
+
+	// report what was done (if anything); this aggregates all secret operations of this reconciliation into one event
+	if clusterSecret != nil && summary.changed() {
+		c.normalEventf(EventVerbositySummary, clusterSecret, "SecretsReconciled", "Reconciled secret %s: %s (deletion policy %s)", common.GetSecretName(clusterSecret), &summary, deletionPolicy)
+	}
+
+	// update distribution status (if applicable), i.e. the namespace counters, the failing namespaces, and the conflicting namespaces
+	if clusterSecret != nil && clusterSecret.DeletionTimestamp.IsZero() {
+		message := ""
+		if merr != nil {
+			message = merr.Error()
+		}
+		distributionStatus := buildDistributionStatus(matchedNamespaces, failures, conflictingNamespaces, message)
+		if err := c.updateClusterSecretDistributionStatus(ctx, clusterSecret, distributionStatus); err != nil {
+			merr = multierror.Append(merr, err)
 		}
 	}
+
 	if merr.ErrorOrNil() != nil {
 		if clusterSecret != nil {
-			if err := c.updateClusterSecretStatus(clusterSecret, corev1alpha1.StateError); err != nil {
-				multierror.Append(merr, err)
+			if err := c.updateClusterSecretStatus(ctx, clusterSecret, corev1alpha1.StateError, merr.Error()); err != nil {
+				merr = multierror.Append(merr, err)
 			}
 		}
 		if clusterSecret != nil {
@@ -273,19 +403,17 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 		}
 		return merr
 	}
-	if clusterSecret != nil {
-		c.eventRecorder.Eventf(clusterSecret, corev1.EventTypeNormal, "ClusterSecretReconcile", "Successfully reconciled clustersecret %s", clusterSecret.Name)
-	}
 	if clusterSecret != nil && clusterSecret.DeletionTimestamp.IsZero() {
-		if err := c.updateClusterSecretStatus(clusterSecret, corev1alpha1.StateReady); err != nil {
+		if err := c.updateClusterSecretStatus(ctx, clusterSecret, corev1alpha1.StateReady, ""); err != nil {
 			c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
 			return err
 		}
 	}
 
-	// unset finalizer
+	// unset finalizer; at this point, all managed secrets have been deleted or orphaned (according to the deletion policy)
 	if clusterSecret != nil && !clusterSecret.DeletionTimestamp.IsZero() {
-		if err := c.unsetClusterSecretFinalizer(clusterSecret); err != nil {
+		c.normalEventf(EventVerbositySummary, clusterSecret, "ClusterSecretFinalize", "Releasing clustersecret %s (deletion policy %s)", clusterSecret.Name, deletionPolicy)
+		if err := c.unsetClusterSecretFinalizer(ctx, clusterSecret); err != nil {
 			c.eventRecorder.Event(clusterSecret, corev1.EventTypeWarning, "Error", err.Error())
 			return err
 		}
@@ -294,3 +422,141 @@ func (c *Controller) reconcileClusterSecret(clusterSecretName string) error {
 	// return
 	return nil
 }
+
+// reconcile a single secret, according to the given operation; returns the performed action (empty if nothing was done); in case of errors,
+// a message describing the failed step is returned as well; in addition, according (detailed) events are emitted on the secret
+func (c *Controller) reconcileSecret(ctx context.Context, clusterSecretName string, key secretKey, operation *secretOperation, deletionPolicy corev1alpha1.DeletionPolicy) (string, string, error) {
+	action, message, err := c.applySecretOperation(ctx, key, operation, deletionPolicy)
+	object := operation.new
+	if object == nil {
+		object = operation.old
+	}
+	if err != nil {
+		c.warningEventf(EventVerbosityDetailed, object, "ReconcileError", "Error reconciling secret (managed by clustersecret %s): %s", clusterSecretName, err)
+	} else if action != "" {
+		c.normalEventf(EventVerbosityDetailed, object, action, "%s secret (managed by clustersecret %s)", action, clusterSecretName)
+	}
+	return action, message, err
+}
+
+func (c *Controller) applySecretOperation(ctx context.Context, key secretKey, operation *secretOperation, deletionPolicy corev1alpha1.DeletionPolicy) (string, string, error) {
+	logger := klog.FromContext(ctx).WithValues("namespace", key.namespace, "secret", key.name)
+	ctx, span := tracing.Tracer().Start(ctx, "reconcile secret", trace.WithAttributes(
+		tracing.AttributeKeyNamespace.String(key.namespace),
+		tracing.AttributeKeySecret.String(key.name),
+	))
+	defer span.End()
+	// note: reconciling a clustersecret which selects many namespaces may take longer than the liveness window, so every
+	// completed secret operation (successful or not) counts as progress
+	defer c.recordProgress()
+
+	if operation.new == nil && deletionPolicy == corev1alpha1.DeletionPolicyOrphan {
+		// this is an orphaning; the secret (including its data) is kept, but the controller's labels and annotations are removed
+		// note: we can assume that operation.old is not nil because of the way how operations was defined
+		logger.V(2).Info("orphaning secret")
+		span.SetAttributes(tracing.AttributeKeyOperation.String("orphan"))
+		secret, err := c.kubeclient.CoreV1().Secrets(key.namespace).Update(
+			ctx,
+			buildOrphanedSecret(operation.old),
+			metav1.UpdateOptions{FieldManager: common.ControllerName},
+		)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				tracing.RecordError(span, err)
+				return "", fmt.Sprintf("error orphaning secret %s/%s", key.namespace, key.name), err
+			}
+			return "", "", nil
+		}
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordUpdate(operation.old, secret)
+		}
+		return secretActionOrphaned, "", nil
+	} else if operation.new == nil {
+		// this is a deletion
+		// note: we can assume that operation.old is not nil because of the way how operations was defined
+		logger.V(2).Info("deleting secret (if existing)")
+		span.SetAttributes(tracing.AttributeKeyOperation.String("delete"))
+		err := c.kubeclient.CoreV1().Secrets(key.namespace).Delete(
+			ctx,
+			key.name,
+			metav1.DeleteOptions{Preconditions: &metav1.Preconditions{ResourceVersion: &operation.old.ResourceVersion}},
+		)
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordDeletion(operation.old)
+		}
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return "", "", nil
+			}
+			tracing.RecordError(span, err)
+			return "", fmt.Sprintf("error deleting secret %s/%s", key.namespace, key.name), err
+		}
+		return secretActionDeleted, "", nil
+	} else if operation.old == nil {
+		// this is a creation
+		// note: this can fail in particular if the secret already exists, but is not managed by us
+		logger.V(2).Info("creating secret")
+		span.SetAttributes(tracing.AttributeKeyOperation.String("create"))
+		secret, err := c.kubeclient.CoreV1().Secrets(key.namespace).Create(
+			ctx,
+			operation.new,
+			metav1.CreateOptions{FieldManager: common.ControllerName},
+		)
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordCreation(secret)
+		}
+		if err != nil {
+			tracing.RecordError(span, err)
+			return "", fmt.Sprintf("error creating secret %s/%s", key.namespace, key.name), err
+		}
+		return secretActionCreated, "", nil
+	} else if operation.recreate {
+		// this is a recreation (i.e. a deletion followed by a creation); the deletion is guarded by the resourceVersion of the existing secret,
+		// such that concurrent changes are not overwritten (in that case, the next reconciliation will try again)
+		logger.V(2).Info("recreating secret")
+		span.SetAttributes(tracing.AttributeKeyOperation.String("recreate"))
+		err := c.kubeclient.CoreV1().Secrets(key.namespace).Delete(
+			ctx,
+			key.name,
+			metav1.DeleteOptions{Preconditions: &metav1.Preconditions{ResourceVersion: &operation.old.ResourceVersion}},
+		)
+		if err != nil && !errors.IsNotFound(err) {
+			tracing.RecordError(span, err)
+			return "", fmt.Sprintf("error deleting secret %s/%s (for recreation)", key.namespace, key.name), err
+		}
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordDeletion(operation.old)
+		}
+		operation.new.ResourceVersion = ""
+		secret, err := c.kubeclient.CoreV1().Secrets(key.namespace).Create(
+			ctx,
+			operation.new,
+			metav1.CreateOptions{FieldManager: common.ControllerName},
+		)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return "", fmt.Sprintf("error creating secret %s/%s (for recreation)", key.namespace, key.name), err
+		}
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordCreation(secret)
+		}
+		return secretActionRecreated, "", nil
+	} else {
+		// this is an update
+		logger.V(2).Info("updating secret")
+		span.SetAttributes(tracing.AttributeKeyOperation.String("update"))
+		secret, err := c.kubeclient.CoreV1().Secrets(key.namespace).Update(
+			ctx,
+			operation.new,
+			metav1.UpdateOptions{FieldManager: common.ControllerName},
+		)
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordUpdate(operation.old, secret)
+		}
+		if err != nil {
+			tracing.RecordError(span, err)
+			return "", fmt.Sprintf("error updating secret %s/%s", key.namespace, key.name), err
+		}
+		return secretActionUpdated, "", nil
+	}
+}
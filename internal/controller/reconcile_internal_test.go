@@ -7,9 +7,24 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/sap/clustersecret-operator/internal/common"
 	"github.com/sap/clustersecret-operator/test"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 )
 
 // test: create clustersecrets
@@ -23,11 +38,11 @@ func TestReconcile1(t *testing.T) {
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	c := NewController(ctx, env.KubernetesClient(), env.CoreClient(), env.NewSynchronizer())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
 	c.startInformers()
 	defer cancel()
 
-	c.reconcileClusterSecret("my-secret")
+	c.reconcileClusterSecret(ctx, "my-secret")
 	env.MustError(t).AssertSecretFromFile("secret.yaml")
 }
 
@@ -46,11 +61,11 @@ func TestReconcile2(t *testing.T) {
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	c := NewController(ctx, env.KubernetesClient(), env.CoreClient(), env.NewSynchronizer())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
 	c.startInformers()
 	defer cancel()
 
-	c.reconcileClusterSecret("my-secret")
+	c.reconcileClusterSecret(ctx, "my-secret")
 	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
 	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
 	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
@@ -58,7 +73,763 @@ func TestReconcile2(t *testing.T) {
 	env.MustFatal(t).UnlabelNamespace("my-namespace-1", "mylabel")
 	env.MustFatal(t).LabelNamespace("my-namespace-2", "mylabel", "othervalue")
 	env.MustFatal(t).LabelNamespace("my-namespace-3", "mylabel", "myvalue")
-	c.reconcileClusterSecret("my-secret")
+	c.reconcileClusterSecret(ctx, "my-secret")
 	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
 	env.MustError(t).AssertSecretFromFile("secret-3.yaml")
 }
+
+// test: templated clustersecrets with render errors
+func TestReconcile3(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/6")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err == nil {
+		t.Error("expected reconcile error, got none")
+	}
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	if clusterSecret.Status.State != corev1alpha1.StateError {
+		t.Errorf("expected state %s, got %s", corev1alpha1.StateError, clusterSecret.Status.State)
+	}
+	if message := getReadyCondition(clusterSecret).Message; !strings.Contains(message, "my-namespace-2") {
+		t.Errorf("expected status message to mention namespace my-namespace-2, got: %s", message)
+	}
+	if status := clusterSecret.Status; status.MatchedNamespaces != 2 || status.SyncedNamespaces != 1 || status.FailedNamespaces != 1 {
+		t.Errorf("expected matched/synced/failed namespaces 2/1/1, got %d/%d/%d", status.MatchedNamespaces, status.SyncedNamespaces, status.FailedNamespaces)
+	}
+	if failures := clusterSecret.Status.Failures; len(failures) != 1 || failures[0].Namespace != "my-namespace-2" || failures[0].Message == "" {
+		t.Errorf("expected failure for namespace my-namespace-2, got %v", failures)
+	}
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeSynced, corev1.ConditionFalse, "Failed")
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeDegraded, corev1.ConditionTrue, "PartiallyFailed")
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeConflict, corev1.ConditionFalse, "NoConflicts")
+	if message := getClusterSecretCondition(clusterSecret, corev1alpha1.ClusterSecretConditionTypeDegraded).Message; !strings.Contains(message, "my-namespace-2") {
+		t.Errorf("expected degraded message to mention namespace my-namespace-2, got: %s", message)
+	}
+}
+
+// test: clustersecrets with missing source secret
+func TestReconcile4(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/9")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace.yaml",
+		"namespace-platform.yaml",
+		"secret.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Errorf("expected no reconcile error, got: %s", err)
+	}
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret.yaml")
+
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	if clusterSecret.Status.State != corev1alpha1.StateError {
+		t.Errorf("expected state %s, got %s", corev1alpha1.StateError, clusterSecret.Status.State)
+	}
+	if reason := getReadyCondition(clusterSecret).Reason; reason != "SourceMissing" {
+		t.Errorf("expected condition reason SourceMissing, got: %s", reason)
+	}
+
+	env.MustFatal(t).CreateSecretFromFile("source-updated.yaml")
+	c.reconcileClusterSecret(ctx, "my-secret")
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret-updated.yaml")
+
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	if clusterSecret.Status.State != corev1alpha1.StateReady {
+		t.Errorf("expected state %s, got %s", corev1alpha1.StateReady, clusterSecret.Status.State)
+	}
+}
+
+// test: clustersecrets with data overrides
+func TestReconcile5(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/10")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	c.reconcileClusterSecret(ctx, "my-secret")
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
+
+	env.MustFatal(t).LabelNamespace("my-namespace-2", "stage", "dev")
+	c.reconcileClusterSecret(ctx, "my-secret")
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2-updated.yaml")
+}
+
+// test: orphan secrets of clustersecrets in deletion (deletion policy Orphan)
+func TestReconcile6(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/12")
+
+	env.AddObjectsFromFiles(
+		"clustersecret-deleting.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+		"secret-1.yaml",
+		"secret-2.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name", 0)
+	env.MustError(t).AssertSecretFromFile("secret-1-orphaned.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2-orphaned.yaml")
+
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	if len(clusterSecret.Finalizers) > 0 {
+		t.Errorf("expected finalizers to be removed, got %v", clusterSecret.Finalizers)
+	}
+}
+
+// test: existing unmanaged secrets (conflict policies)
+func TestReconcile7(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/13")
+
+	env.AddObjectsFromFiles(
+		"clustersecret-fail.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+		"secret-2-unmanaged.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err == nil {
+		t.Errorf("expected error, got none")
+	}
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret-2-unmanaged.yaml")
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	if clusterSecret.Status.State != corev1alpha1.StateError {
+		t.Errorf("expected state %s, got %s", corev1alpha1.StateError, clusterSecret.Status.State)
+	}
+	if !reflect.DeepEqual(clusterSecret.Status.ConflictingNamespaces, []string{"my-namespace-2"}) || clusterSecret.Status.ConflictingNamespaceCount != 1 {
+		t.Errorf("expected 1 conflicting namespace [my-namespace-2], got %d %v", clusterSecret.Status.ConflictingNamespaceCount, clusterSecret.Status.ConflictingNamespaces)
+	}
+	if status := clusterSecret.Status; status.MatchedNamespaces != 2 || status.SyncedNamespaces != 1 || status.FailedNamespaces != 1 {
+		t.Errorf("expected matched/synced/failed namespaces 2/1/1, got %d/%d/%d", status.MatchedNamespaces, status.SyncedNamespaces, status.FailedNamespaces)
+	}
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeConflict, corev1.ConditionTrue, "SecretConflict")
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeDegraded, corev1.ConditionTrue, "PartiallyFailed")
+
+	env.MustFatal(t).UpdateClusterSecretFromFile("clustersecret-skip.yaml")
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Error(err)
+	}
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 1)
+	env.MustError(t).AssertSecretFromFile("secret-2-unmanaged.yaml")
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	if clusterSecret.Status.State != corev1alpha1.StateReady {
+		t.Errorf("expected state %s, got %s", corev1alpha1.StateReady, clusterSecret.Status.State)
+	}
+	if !reflect.DeepEqual(clusterSecret.Status.ConflictingNamespaces, []string{"my-namespace-2"}) {
+		t.Errorf("expected conflicting namespaces [my-namespace-2], got %v", clusterSecret.Status.ConflictingNamespaces)
+	}
+	if status := clusterSecret.Status; status.MatchedNamespaces != 2 || status.SyncedNamespaces != 1 || status.FailedNamespaces != 0 {
+		t.Errorf("expected matched/synced/failed namespaces 2/1/0, got %d/%d/%d", status.MatchedNamespaces, status.SyncedNamespaces, status.FailedNamespaces)
+	}
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeConflict, corev1.ConditionTrue, "SecretConflict")
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeSynced, corev1.ConditionFalse, "Skipped")
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeDegraded, corev1.ConditionFalse, "NoFailures")
+
+	env.MustFatal(t).UpdateClusterSecretFromFile("clustersecret-adopt.yaml")
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Error(err)
+	}
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	if clusterSecret.Status.State != corev1alpha1.StateReady {
+		t.Errorf("expected state %s, got %s", corev1alpha1.StateReady, clusterSecret.Status.State)
+	}
+	if len(clusterSecret.Status.ConflictingNamespaces) > 0 || clusterSecret.Status.ConflictingNamespaceCount != 0 {
+		t.Errorf("expected no conflicting namespaces, got %d %v", clusterSecret.Status.ConflictingNamespaceCount, clusterSecret.Status.ConflictingNamespaces)
+	}
+	if status := clusterSecret.Status; status.MatchedNamespaces != 2 || status.SyncedNamespaces != 2 || status.FailedNamespaces != 0 {
+		t.Errorf("expected matched/synced/failed namespaces 2/2/0, got %d/%d/%d", status.MatchedNamespaces, status.SyncedNamespaces, status.FailedNamespaces)
+	}
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeConflict, corev1.ConditionFalse, "NoConflicts")
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeSynced, corev1.ConditionTrue, "Synced")
+	assertCondition(t, clusterSecret, corev1alpha1.ClusterSecretConditionTypeReady, corev1.ConditionTrue, "ClusterSecretReady")
+}
+
+// test: immutable secrets and type changes (recreation of secrets)
+func TestReconcile8(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/14")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	env.MustError(t).AssertSecretFromFile("secret.yaml")
+	secret := env.MustFatal(t).GetSecret("my-namespace", "my-secret")
+
+	env.MustFatal(t).UpdateClusterSecretFromFile("clustersecret-updated.yaml")
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	env.MustError(t).AssertSecretFromFile("secret-updated.yaml")
+	updatedSecret := env.MustFatal(t).GetSecret("my-namespace", "my-secret")
+	if updatedSecret.UID == secret.UID {
+		t.Errorf("expected immutable secret to be recreated")
+	}
+
+	env.MustFatal(t).UpdateClusterSecretFromFile("clustersecret-type-updated.yaml")
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	env.MustError(t).AssertSecretFromFile("secret-type-updated.yaml")
+	if env.MustFatal(t).GetSecret("my-namespace", "my-secret").UID == updatedSecret.UID {
+		t.Errorf("expected secret to be recreated on type change")
+	}
+}
+
+// test: event aggregation (according to event verbosity)
+func TestReconcile9(t *testing.T) {
+	for _, verbosity := range []EventVerbosity{EventVerbosityWarning, EventVerbositySummary, EventVerbosityDetailed} {
+		env := test.NewEnvironment()
+		env.SetBasePath("testdata/2")
+
+		env.AddObjectsFromFiles(
+			"clustersecret.yaml",
+			"namespace-1.yaml",
+			"namespace-2.yaml",
+			"namespace-3.yaml",
+			"secret-1.yaml",
+			"secret-3.yaml",
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{EventVerbosity: verbosity})
+		recorder := record.NewFakeRecorder(100)
+		c.eventRecorder = recorder
+		c.startInformers()
+
+		if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+			t.Fatal(err)
+		}
+		var expectedEvents []string
+		switch verbosity {
+		case EventVerbositySummary:
+			expectedEvents = []string{
+				"Normal SecretsReconciled Reconciled secret my-secret: 1 created, 0 updated, 0 recreated, 1 deleted, 0 orphaned, 0 failed (deletion policy Delete)",
+			}
+		case EventVerbosityDetailed:
+			expectedEvents = []string{
+				"Normal Created Created secret (managed by clustersecret my-secret)",
+				"Normal Deleted Deleted secret (managed by clustersecret my-secret)",
+				"Normal SecretsReconciled Reconciled secret my-secret: 1 created, 0 updated, 0 recreated, 1 deleted, 0 orphaned, 0 failed (deletion policy Delete)",
+			}
+		}
+		assertEvents(t, recorder, expectedEvents)
+
+		// nothing changed, so no events are expected
+		if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+			t.Fatal(err)
+		}
+		assertEvents(t, recorder, nil)
+
+		cancel()
+	}
+}
+
+// test: revision history and rollback
+func TestReconcile10(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/1")
+
+	env.AddObjectsFromFiles(
+		"namespace.yaml",
+		"clustersecret.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{RevisionNamespace: "revisions", RevisionHistoryLimit: 2})
+	recorder := record.NewFakeRecorder(1000)
+	c.eventRecorder = recorder
+	c.startInformers()
+	defer cancel()
+
+	updateData := func(value string) {
+		clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+		clusterSecret.Spec.Template.Data = map[string][]byte{"mykey": []byte(value)}
+		env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	}
+	reconcile := func(expectedValue string, expectedRevisions ...int64) {
+		t.Helper()
+		if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+			t.Fatal(err)
+		}
+		if value := string(env.MustFatal(t).GetSecret("my-namespace", "my-secret").Data["mykey"]); value != expectedValue {
+			t.Errorf("expected secret value %s, got %s", expectedValue, value)
+		}
+		c.synchronizer.WaitUntilSynced()
+		revisions, err := c.getRevisions("my-secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var numbers []int64
+		for _, revision := range revisions {
+			numbers = append(numbers, revision.Revision)
+			// the template data must be stored in the payload secret of the revision only
+			if strings.Contains(string(revision.Data.Raw), `"data"`) {
+				t.Errorf("expected revision %s not to contain template data", revision.Name)
+			}
+			env.MustFatal(t).GetSecret("revisions", revision.Name)
+		}
+		if !reflect.DeepEqual(numbers, expectedRevisions) {
+			t.Errorf("expected revisions %v, got %v", expectedRevisions, numbers)
+		}
+		env.MustFatal(t).AssertSecretCount("revisions", common.LabelKeyRevisionOf+"=my-secret", len(expectedRevisions))
+	}
+
+	reconcile("myvalue", 1)
+	reconcile("myvalue", 1)
+	updateData("othervalue")
+	reconcile("othervalue", 1, 2)
+	updateData("thirdvalue")
+	reconcile("thirdvalue", 2, 3)
+
+	// roll back to revision 2; this makes revision 2 the latest one
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Annotations = map[string]string{common.AnnotationKeyRollbackTo: "2"}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	reconcile("othervalue", 3, 4)
+	if _, ok := env.MustFatal(t).GetClusterSecret("my-secret").Annotations[common.AnnotationKeyRollbackTo]; ok {
+		t.Errorf("expected rollback annotation to be removed")
+	}
+
+	// roll back to a pruned revision; this fails (but does not change anything)
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Annotations = map[string]string{common.AnnotationKeyRollbackTo: "1"}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	reconcile("othervalue", 3, 4)
+	if _, ok := env.MustFatal(t).GetClusterSecret("my-secret").Annotations[common.AnnotationKeyRollbackTo]; ok {
+		t.Errorf("expected rollback annotation to be removed")
+	}
+
+	// roll back to revision 3, but let the update restoring the template be rejected (as by the admission webhook); this fails, but the
+	// rollback annotation is removed nevertheless
+	env.CoreClient().PrependReactor("update", "clustersecrets", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		if clusterSecret := action.(kubetesting.UpdateAction).GetObject().(*corev1alpha1.ClusterSecret); string(clusterSecret.Spec.Template.Data["mykey"]) == "thirdvalue" {
+			return true, nil, apierrors.NewBadRequest("injected rejection")
+		}
+		return false, nil, nil
+	})
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Annotations = map[string]string{common.AnnotationKeyRollbackTo: "3"}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	reconcile("othervalue", 3, 4)
+	if _, ok := env.MustFatal(t).GetClusterSecret("my-secret").Annotations[common.AnnotationKeyRollbackTo]; ok {
+		t.Errorf("expected rollback annotation to be removed")
+	}
+	assertRollbackFailed := func() {
+		t.Helper()
+		var rollbackFailed bool
+		for len(recorder.Events) > 0 {
+			if event := <-recorder.Events; strings.HasPrefix(event, "Warning RollbackFailed ") {
+				rollbackFailed = true
+			}
+		}
+		if !rollbackFailed {
+			t.Errorf("expected RollbackFailed event")
+		}
+	}
+	assertRollbackFailed()
+
+	// switch to a source secret; rolling back to a revision with data fails (since this would yield both data and sourceRef)
+	env.MustFatal(t).CreateSecret(&corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-source"},
+		Data:       map[string][]byte{"mykey": []byte("sourcevalue")},
+	})
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Spec.SourceRef = &corev1alpha1.SecretReference{Namespace: "my-namespace", Name: "my-source"}
+	clusterSecret.Spec.Template.Data = nil
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	reconcile("sourcevalue", 4, 5)
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Annotations = map[string]string{common.AnnotationKeyRollbackTo: "4"}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	reconcile("sourcevalue", 4, 5)
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	if _, ok := clusterSecret.Annotations[common.AnnotationKeyRollbackTo]; ok {
+		t.Errorf("expected rollback annotation to be removed")
+	}
+	if len(clusterSecret.Spec.Template.Data) > 0 || clusterSecret.Spec.SourceRef == nil {
+		t.Errorf("expected template not to be rolled back")
+	}
+	assertRollbackFailed()
+
+	// record a changed template by two reconciliations in quick succession (i.e. with a possibly stale cache); the template is recorded once
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Spec.Template.Metadata = &corev1alpha1.SecretTemplateMetadata{Labels: map[string]string{"app": "my-app"}}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	reconcile("sourcevalue", 5, 6)
+}
+
+// test: drift audit
+func TestReconcile11(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/2")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+		"namespace-3.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	recorder := record.NewFakeRecorder(100)
+	c.eventRecorder = recorder
+	c.startInformers()
+	defer cancel()
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	// drain events of the reconciliation
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	audit := func(expectedDriftedNamespaces int32) {
+		t.Helper()
+		c.synchronizer.WaitUntilSynced()
+		c.audit(ctx)
+		c.synchronizer.WaitUntilSynced()
+		clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+		if clusterSecret.Status.DriftedNamespaces != expectedDriftedNamespaces {
+			t.Errorf("expected %d drifted namespaces, got %d", expectedDriftedNamespaces, clusterSecret.Status.DriftedNamespaces)
+		}
+	}
+
+	audit(0)
+	assertEvents(t, recorder, nil)
+
+	// modify data of one secret, and annotations of another one
+	secret := env.MustFatal(t).GetSecret("my-namespace-1", "my-secret")
+	secret.Data["mykey"] = []byte("modified")
+	env.MustFatal(t).UpdateSecret(secret)
+	secret = env.MustFatal(t).GetSecret("my-namespace-2", "my-secret")
+	secret.Annotations[common.AnnotationKeyGeneration] = "invalid"
+	env.MustFatal(t).UpdateSecret(secret)
+	audit(2)
+	assertEvents(t, recorder, []string{"Warning SecretDrift Secret my-secret was modified in 2 namespace(s): my-namespace-1, my-namespace-2"})
+
+	// audit does not fix the drift
+	if value := string(env.MustFatal(t).GetSecret("my-namespace-1", "my-secret").Data["mykey"]); value != "modified" {
+		t.Errorf("expected secret to be left untouched by audit")
+	}
+
+	// reconciliation (after a change of the clustersecret) fixes the drift, which is then reflected by the next audit
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Generation++
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	audit(0)
+	assertEvents(t, recorder, nil)
+
+	// a change of the clustersecret which does not affect the rendered secrets (here: of the namespace selector) does not rewrite the secrets;
+	// nevertheless, subsequent modifications of the secrets are still reported as drift
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "mylabel", Operator: metav1.LabelSelectorOpExists}},
+	}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	secret = env.MustFatal(t).GetSecret("my-namespace-2", "my-secret")
+	if generation := secret.Annotations[common.AnnotationKeyGeneration]; generation == strconv.FormatInt(env.MustFatal(t).GetClusterSecret("my-secret").Generation, 10) {
+		t.Errorf("expected secret not to be rewritten by selector change")
+	}
+	secret.Data["mykey"] = []byte("modified")
+	env.MustFatal(t).UpdateSecret(secret)
+	audit(1)
+	assertEvents(t, recorder, []string{"Warning SecretDrift Secret my-secret was modified in 1 namespace(s): my-namespace-2"})
+
+	// modifications of the data together with the content hash annotation (removing it, setting it to an invalid value, or to the hash
+	// of the modified content) are reported as drift as well
+	secret = env.MustFatal(t).GetSecret("my-namespace-1", "my-secret")
+	secret.Data["mykey"] = []byte("modified")
+	delete(secret.Annotations, common.AnnotationKeyContentHash)
+	env.MustFatal(t).UpdateSecret(secret)
+	secret = env.MustFatal(t).GetSecret("my-namespace-2", "my-secret")
+	contentHash, err := computeSecretContentHash(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret.Annotations[common.AnnotationKeyContentHash] = contentHash
+	env.MustFatal(t).UpdateSecret(secret)
+	audit(2)
+	assertEvents(t, recorder, []string{"Warning SecretDrift Secret my-secret was modified in 2 namespace(s): my-namespace-1, my-namespace-2"})
+	secret = env.MustFatal(t).GetSecret("my-namespace-1", "my-secret")
+	secret.Annotations[common.AnnotationKeyContentHash] = "invalid"
+	env.MustFatal(t).UpdateSecret(secret)
+	audit(2)
+	assertEvents(t, recorder, []string{"Warning SecretDrift Secret my-secret was modified in 2 namespace(s): my-namespace-1, my-namespace-2"})
+
+	// secrets written from a previous template are not reported as long as the rollout of the current template is pending
+	clusterSecret = env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Spec.Template.Data = map[string][]byte{"mykey": []byte("othervalue")}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	audit(0)
+	assertEvents(t, recorder, nil)
+}
+
+func assertCondition(t *testing.T, clusterSecret *corev1alpha1.ClusterSecret, conditionType corev1alpha1.ClusterSecretConditionType, status corev1.ConditionStatus, reason string) {
+	t.Helper()
+	condition := getClusterSecretCondition(clusterSecret, conditionType)
+	if condition == nil {
+		t.Errorf("expected condition %s, found none", conditionType)
+		return
+	}
+	if condition.Status != status || condition.Reason != reason {
+		t.Errorf("expected condition %s to be %s (%s), got %s (%s)", conditionType, status, reason, condition.Status, condition.Reason)
+	}
+	if condition.ObservedGeneration != clusterSecret.Generation {
+		t.Errorf("expected condition %s to have observed generation %d, got %d", conditionType, clusterSecret.Generation, condition.ObservedGeneration)
+	}
+}
+
+func assertEvents(t *testing.T, recorder *record.FakeRecorder, expectedEvents []string) {
+	t.Helper()
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	// note: secrets are reconciled in random order
+	sort.Strings(events)
+	if !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("expected events %q, got %q", expectedEvents, events)
+	}
+}
+
+// test: content hash (changes not affecting the rendered secrets cause no secret writes)
+func TestReconcile12(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/2")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+		"namespace-3.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{})
+	c.startInformers()
+	defer cancel()
+
+	numSecretWrites := 0
+	countSecretWrite := func(object runtime.Object) {
+		if _, ok := object.(*corev1.Secret); ok {
+			numSecretWrites++
+		}
+	}
+	env.RegisterCreateCallback(countSecretWrite)
+	env.RegisterUpdateCallback(func(oldObject runtime.Object, newObject runtime.Object) { countSecretWrite(newObject) })
+	env.RegisterDeleteCallback(countSecretWrite)
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
+	if numSecretWrites != 2 {
+		t.Errorf("expected 2 secret writes, got %d", numSecretWrites)
+	}
+
+	// change the namespace selector (without changing the set of selected namespaces); this must not cause any secret writes
+	numSecretWrites = 0
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	clusterSecret.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "mylabel", Operator: metav1.LabelSelectorOpExists}},
+	}
+	env.MustFatal(t).UpdateClusterSecret(clusterSecret)
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if numSecretWrites != 0 {
+		t.Errorf("expected no secret writes, got %d", numSecretWrites)
+	}
+	// note: the generation annotation still reflects the generation which last wrote the secrets
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-2.yaml")
+
+	// existing secrets without content hash are updated once
+	secret := env.MustFatal(t).GetSecret("my-namespace-1", "my-secret")
+	delete(secret.Annotations, common.AnnotationKeyContentHash)
+	env.MustFatal(t).UpdateSecret(secret)
+	numSecretWrites = 0
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if numSecretWrites != 1 {
+		t.Errorf("expected 1 secret write, got %d", numSecretWrites)
+	}
+	if hash := env.MustFatal(t).GetSecret("my-namespace-1", "my-secret").Annotations[common.AnnotationKeyContentHash]; hash != "99219d55b0dceca13a454cc8073868a797f3b115754d6a9e83614c97b2588e52" {
+		t.Errorf("expected content hash to be restored, got %q", hash)
+	}
+}
+
+// test: secrets are written in parallel (with bounded concurrency), and the results are aggregated correctly
+func TestReconcile13(t *testing.T) {
+	env := test.NewEnvironment()
+	env.SetBasePath("testdata/2")
+
+	env.AddObjectsFromFiles(
+		"clustersecret.yaml",
+		"namespace-1.yaml",
+		"namespace-2.yaml",
+		"namespace-3.yaml",
+		"namespace-4.yaml",
+		"namespace-5.yaml",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewController(ctx, env.KubernetesClient(), env.MetadataClient(), env.CoreClient(), env.NewSynchronizer(), Options{SecretWriteConcurrency: 2})
+	c.startInformers()
+	defer cancel()
+
+	// let the creation of the secret in one namespace fail
+	env.KubernetesClient().PrependReactor("create", "secrets", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "my-namespace-2" {
+			return true, nil, fmt.Errorf("injected error")
+		}
+		return false, nil, nil
+	})
+
+	if err := c.reconcileClusterSecret(ctx, "my-secret"); err == nil {
+		t.Errorf("expected error, got none")
+	} else if !strings.Contains(err.Error(), "error creating secret my-namespace-2/my-secret") || !strings.Contains(err.Error(), "injected error") {
+		t.Errorf("unexpected error: %s", err)
+	}
+	env.MustError(t).AssertSecretCount("", "clustersecrets.core.cs.sap.com/name=my-secret", 2)
+	env.MustError(t).AssertSecretFromFile("secret-1.yaml")
+	env.MustError(t).AssertSecretFromFile("secret-5.yaml")
+	clusterSecret := env.MustFatal(t).GetClusterSecret("my-secret")
+	if status := clusterSecret.Status; status.MatchedNamespaces != 3 || status.SyncedNamespaces != 2 || status.FailedNamespaces != 1 {
+		t.Errorf("expected matched/synced/failed namespaces 3/2/1, got %d/%d/%d", status.MatchedNamespaces, status.SyncedNamespaces, status.FailedNamespaces)
+	}
+}
+
+// test: distribution status (only failures in selected namespaces are counted and listed, with truncated messages; conflicting namespaces
+// are counted, but listed only up to maxStatusConflictingNamespaces)
+func TestBuildDistributionStatus(t *testing.T) {
+	failures := map[string]string{
+		"my-namespace-2": strings.Repeat("x", 2*maxConditionMessageLength),
+		"my-namespace-4": "error deleting secret my-namespace-4/my-secret",
+	}
+	status := buildDistributionStatus([]string{"my-namespace-1", "my-namespace-2", "my-namespace-3"}, failures, []string{"my-namespace-3"}, "some error")
+	if status.MatchedNamespaces != 3 || status.SyncedNamespaces != 1 || status.FailedNamespaces != 1 {
+		t.Errorf("expected matched/synced/failed namespaces 3/1/1, got %d/%d/%d", status.MatchedNamespaces, status.SyncedNamespaces, status.FailedNamespaces)
+	}
+	if len(status.Failures) != 1 || status.Failures[0].Namespace != "my-namespace-2" || len(status.Failures[0].Message) > maxConditionMessageLength {
+		t.Errorf("expected one (truncated) failure in namespace my-namespace-2, got %v", status.Failures)
+	}
+
+	status = buildDistributionStatus([]string{"my-namespace-1"}, map[string]string{"my-namespace-4": "some error"}, nil, "some error")
+	if status.SyncedNamespaces != 1 || status.FailedNamespaces != 0 || len(status.Failures) != 0 {
+		t.Errorf("expected synced/failed namespaces 1/0 without failures, got %d/%d (%v)", status.SyncedNamespaces, status.FailedNamespaces, status.Failures)
+	}
+	for _, condition := range status.Conditions {
+		if condition.Type == corev1alpha1.ClusterSecretConditionTypeDegraded && (condition.Status != corev1.ConditionTrue || condition.Reason != "PartiallyFailed") {
+			t.Errorf("expected condition %s to be %s (%s), got %s (%s)", condition.Type, corev1.ConditionTrue, "PartiallyFailed", condition.Status, condition.Reason)
+		}
+	}
+
+	var namespaces []string
+	for i := 25; i > 0; i-- {
+		namespaces = append(namespaces, fmt.Sprintf("my-namespace-%02d", i))
+	}
+	status = buildDistributionStatus(namespaces, nil, namespaces, "")
+	if status.ConflictingNamespaceCount != 25 || status.SyncedNamespaces != 0 {
+		t.Errorf("expected conflicting/synced namespaces 25/0, got %d/%d", status.ConflictingNamespaceCount, status.SyncedNamespaces)
+	}
+	if len(status.ConflictingNamespaces) != maxStatusConflictingNamespaces || status.ConflictingNamespaces[0] != "my-namespace-01" {
+		t.Errorf("expected the first %d conflicting namespaces (sorted), got %v", maxStatusConflictingNamespaces, status.ConflictingNamespaces)
+	}
+	for _, condition := range status.Conditions {
+		if condition.Type == corev1alpha1.ClusterSecretConditionTypeConflict && (!strings.HasPrefix(condition.Message, "unmanaged secrets exist in 25 namespace(s): my-namespace-01, ") || !strings.HasSuffix(condition.Message, "my-namespace-10, ...")) {
+			t.Errorf("unexpected message of condition %s: %s", condition.Type, condition.Message)
+		}
+	}
+}
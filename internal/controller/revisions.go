@@ -0,0 +1,347 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/sap/clustersecret-operator/internal/common"
+
+	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
+)
+
+var controllerRevisionGroupVersionKind = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ControllerRevision"}
+
+// whether revision history is enabled
+func (c *Controller) revisionHistoryEnabled() bool {
+	return c.revisionNamespace != "" && c.revisionHistoryLimit > 0
+}
+
+// compute hash of a secret template; the hash is used as label value and as part of the revision name, so it must be short enough
+func computeTemplateHash(template *corev1alpha1.SecretTemplateSpec) (string, error) {
+	raw, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// return a copy of the given template without data; the data of a revision is not stored in the revision itself (which is readable
+// by everyone allowed to read controllerrevisions), but in the according payload secret
+func stripTemplateData(template *corev1alpha1.SecretTemplateSpec) *corev1alpha1.SecretTemplateSpec {
+	template = template.DeepCopy()
+	template.Data = nil
+	template.StringData = nil
+	return template
+}
+
+// build owner references of revisions (and their payload secrets) of the given clustersecret
+func buildRevisionOwnerReferences(clusterSecret *corev1alpha1.ClusterSecret) []metav1.OwnerReference {
+	// note: the owner reference makes the garbage collector delete all revisions once the clustersecret is deleted
+	return []metav1.OwnerReference{
+		{
+			APIVersion: corev1alpha1.ClusterSecretGroupVersionKind.GroupVersion().String(),
+			Kind:       corev1alpha1.ClusterSecretGroupVersionKind.Kind,
+			Name:       clusterSecret.Name,
+			UID:        clusterSecret.UID,
+		},
+	}
+}
+
+// build name of the revision of the given clustersecret with the given template hash
+func buildRevisionName(clusterSecretName string, hash string) string {
+	// note: revision names must not exceed 253 characters
+	if maxLength := 253 - len(hash) - 1; len(clusterSecretName) > maxLength {
+		clusterSecretName = clusterSecretName[:maxLength]
+	}
+	return clusterSecretName + "-" + hash
+}
+
+// get all revisions of the given clustersecret, ordered by revision number (ascending)
+func (c *Controller) getRevisions(clusterSecretName string) ([]*appsv1.ControllerRevision, error) {
+	revisions, err := c.revisionLister.ControllerRevisions(c.revisionNamespace).List(labels.SelectorFromSet(labels.Set{common.LabelKeyName: clusterSecretName}))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// same as getRevisions, but reading the revisions from the api server instead of the (possibly stale) cache
+func (c *Controller) listRevisions(ctx context.Context, clusterSecretName string) ([]*appsv1.ControllerRevision, error) {
+	revisionList, err := c.kubeclient.AppsV1().ControllerRevisions(c.revisionNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{common.LabelKeyName: clusterSecretName}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]*appsv1.ControllerRevision, len(revisionList.Items))
+	for i := range revisionList.Items {
+		revisions[i] = &revisionList.Items[i]
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// record the current template of the given clustersecret as new revision (if it differs from the latest revision), and prune revisions
+// exceeding the configured history limit; if the template matches an older revision (e.g. after a rollback), that revision becomes the latest one
+func (c *Controller) recordRevision(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret) error {
+	logger := klog.FromContext(ctx)
+
+	hash, err := computeTemplateHash(&clusterSecret.Spec.Template)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(stripTemplateData(&clusterSecret.Spec.Template))
+	if err != nil {
+		return err
+	}
+	isLatestRevision := func(revisions []*appsv1.ControllerRevision) bool {
+		return len(revisions) > 0 && revisions[len(revisions)-1].Labels[common.LabelKeyTemplateHash] == hash
+	}
+	revisions, err := c.getRevisions(clusterSecret.Name)
+	if err != nil {
+		return err
+	}
+	if isLatestRevision(revisions) {
+		return nil
+	}
+	// note: the cache may be stale (e.g. if a revision was recorded by a reconciliation shortly before); in order not to record the same template
+	// twice, or to assign the same revision number twice, the revisions are read from the api server before recording a new one
+	revisions, err = c.listRevisions(ctx, clusterSecret.Name)
+	if err != nil {
+		return err
+	}
+	if isLatestRevision(revisions) {
+		return nil
+	}
+	var latestRevision int64
+	if len(revisions) > 0 {
+		latestRevision = revisions[len(revisions)-1].Revision
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	var existingRevision *appsv1.ControllerRevision
+	for _, revision := range revisions {
+		if revision.Labels[common.LabelKeyTemplateHash] == hash {
+			existingRevision = revision
+		}
+	}
+	revisionName := buildRevisionName(clusterSecret.Name, hash)
+	if existingRevision != nil {
+		revisionName = existingRevision.Name
+	}
+	// note: the payload secret is created before the revision, such that it is never missing for a recorded revision
+	if err := c.createRevisionPayload(ctx, clusterSecret, revisionName, hash); err != nil {
+		return err
+	}
+	if existingRevision != nil {
+		logger.V(1).Info("reusing revision", "revision", existingRevision.Name, "number", latestRevision+1)
+		newRevision := existingRevision.DeepCopy()
+		newRevision.Revision = latestRevision + 1
+		// note: revisions recorded by earlier versions of the controller contain the template data, which is now dropped
+		newRevision.Data = runtime.RawExtension{Raw: raw}
+		if newRevision.Annotations == nil {
+			newRevision.Annotations = make(map[string]string)
+		}
+		newRevision.Annotations[common.AnnotationKeyRecordedAt] = now
+		updatedRevision, err := c.kubeclient.AppsV1().ControllerRevisions(c.revisionNamespace).Update(ctx, newRevision, metav1.UpdateOptions{FieldManager: common.ControllerName})
+		if err != nil {
+			return err
+		}
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordUpdate(existingRevision, updatedRevision)
+		}
+		var otherRevisions []*appsv1.ControllerRevision
+		for _, revision := range revisions {
+			if revision.UID != existingRevision.UID {
+				otherRevisions = append(otherRevisions, revision)
+			}
+		}
+		revisions = append(otherRevisions, updatedRevision)
+	} else {
+		revision := &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: c.revisionNamespace,
+				Name:      revisionName,
+				Labels: map[string]string{
+					common.LabelKeyName:         clusterSecret.Name,
+					common.LabelKeyTemplateHash: hash,
+				},
+				Annotations: map[string]string{
+					common.AnnotationKeyRecordedAt: now,
+				},
+				OwnerReferences: buildRevisionOwnerReferences(clusterSecret),
+			},
+			Data:     runtime.RawExtension{Raw: raw},
+			Revision: latestRevision + 1,
+		}
+		logger.V(1).Info("recording revision", "revision", revision.Name, "number", revision.Revision)
+		createdRevision, err := c.kubeclient.AppsV1().ControllerRevisions(c.revisionNamespace).Create(ctx, revision, metav1.CreateOptions{FieldManager: common.ControllerName})
+		if errors.IsAlreadyExists(err) {
+			// the template was recorded concurrently (e.g. by a previous leader); the next reconciliation will make it the latest revision, if necessary
+			logger.V(1).Info("revision already exists", "revision", revision.Name)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordCreation(createdRevision)
+		}
+		revisions = append(revisions, createdRevision)
+	}
+
+	// prune revisions exceeding the history limit (revisions are still ordered ascending by revision number)
+	for len(revisions) > c.revisionHistoryLimit {
+		revision := revisions[0]
+		logger.V(1).Info("pruning revision", "revision", revision.Name, "number", revision.Revision)
+		// note: the payload secret is deleted first, such that it is not orphaned if deleting the revision fails (and is retried)
+		if err := c.kubeclient.CoreV1().Secrets(c.revisionNamespace).Delete(ctx, revision.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err := c.kubeclient.AppsV1().ControllerRevisions(c.revisionNamespace).Delete(
+			ctx,
+			revision.Name,
+			metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &revision.UID}},
+		); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if recorder, ok := c.synchronizer.(Recorder); ok {
+			recorder.RecordDeletion(revision)
+		}
+		revisions = revisions[1:]
+	}
+
+	return nil
+}
+
+// create the payload secret of the revision with the given name, containing the template data of the given clustersecret (if not yet existing);
+// note: payload secrets are not watched by any informer (they are only read when rolling back), so writes to them are not recorded
+func (c *Controller) createRevisionPayload(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret, revisionName string, hash string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: c.revisionNamespace,
+			Name:      revisionName,
+			Labels: map[string]string{
+				common.LabelKeyRevisionOf:   clusterSecret.Name,
+				common.LabelKeyTemplateHash: hash,
+			},
+			OwnerReferences: buildRevisionOwnerReferences(clusterSecret),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: clusterSecret.Spec.Template.Data,
+	}
+	if _, err := c.kubeclient.CoreV1().Secrets(c.revisionNamespace).Create(ctx, secret, metav1.CreateOptions{FieldManager: common.ControllerName}); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// get the data stored in the payload secret of the given revision; returns nil if there is no payload secret
+// (as is the case for revisions recorded by earlier versions of the controller, which contain the template data themselves)
+func (c *Controller) getRevisionPayload(ctx context.Context, revision *appsv1.ControllerRevision) (map[string][]byte, error) {
+	secret, err := c.kubeclient.CoreV1().Secrets(c.revisionNamespace).Get(ctx, revision.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// roll back the template of the given clustersecret to the revision named in the rollback annotation (which is removed in any case);
+// returns the updated clustersecret, whose (rolled back) template is then distributed as usual by the calling reconciliation;
+// if the update restoring the template is rejected (e.g. by the admission webhook), the annotation is removed by a separate update,
+// such that the rollback is not retried over and over again
+func (c *Controller) rollbackClusterSecret(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret) (*corev1alpha1.ClusterSecret, error) {
+	value := clusterSecret.Annotations[common.AnnotationKeyRollbackTo]
+	newClusterSecret := clusterSecret.DeepCopy()
+	delete(newClusterSecret.Annotations, common.AnnotationKeyRollbackTo)
+
+	var rollbackErr error
+	var template *corev1alpha1.SecretTemplateSpec
+	if number, err := strconv.ParseInt(value, 10, 64); err != nil || number <= 0 {
+		rollbackErr = fmt.Errorf("invalid revision %q (must be a positive integer)", value)
+	} else if revisions, err := c.getRevisions(clusterSecret.Name); err != nil {
+		return nil, err
+	} else {
+		for _, revision := range revisions {
+			if revision.Revision == number {
+				template = &corev1alpha1.SecretTemplateSpec{}
+				if err := json.Unmarshal(revision.Data.Raw, template); err != nil {
+					rollbackErr = fmt.Errorf("error decoding revision %d (%s): %s", number, revision.Name, err)
+					break
+				}
+				data, err := c.getRevisionPayload(ctx, revision)
+				if err != nil {
+					return nil, err
+				}
+				if data != nil {
+					template.Data = data
+				}
+				// note: the template hash detects missing or mismatching payload secrets
+				if hash, err := computeTemplateHash(template); err != nil || hash != revision.Labels[common.LabelKeyTemplateHash] {
+					rollbackErr = fmt.Errorf("payload of revision %d (%s) is missing or does not match the revision", number, revision.Name)
+				}
+				break
+			}
+		}
+		if template == nil && rollbackErr == nil {
+			rollbackErr = fmt.Errorf("revision %d not found", number)
+		}
+		// note: the source reference is not part of the revision, so the restored template must be consistent with the current one
+		if rollbackErr == nil && clusterSecret.Spec.SourceRef != nil && len(template.Data) > 0 {
+			rollbackErr = fmt.Errorf("revision %d contains data, but clustersecret has a sourceRef (exactly one of template.data and sourceRef must be set)", number)
+		}
+		if rollbackErr == nil && clusterSecret.Spec.SourceRef == nil && len(template.Data) == 0 {
+			rollbackErr = fmt.Errorf("revision %d contains no data, and clustersecret has no sourceRef (exactly one of template.data and sourceRef must be set)", number)
+		}
+	}
+	if rollbackErr == nil {
+		rolledBackClusterSecret := newClusterSecret.DeepCopy()
+		rolledBackClusterSecret.Spec.Template = *template
+		updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Update(ctx, rolledBackClusterSecret, metav1.UpdateOptions{FieldManager: common.ControllerName})
+		if err == nil {
+			if recorder, ok := c.synchronizer.(Recorder); ok {
+				recorder.RecordUpdate(clusterSecret, updatedClusterSecret)
+			}
+			c.normalEventf(EventVerbositySummary, updatedClusterSecret, "RolledBack", "Rolled back template of clustersecret %s to revision %s", clusterSecret.Name, value)
+			return updatedClusterSecret, nil
+		}
+		if !errors.IsBadRequest(err) && !errors.IsInvalid(err) && !errors.IsForbidden(err) {
+			return nil, err
+		}
+		rollbackErr = fmt.Errorf("update rejected: %s", err)
+	}
+
+	updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Update(ctx, newClusterSecret, metav1.UpdateOptions{FieldManager: common.ControllerName})
+	if err != nil {
+		return nil, err
+	}
+	if recorder, ok := c.synchronizer.(Recorder); ok {
+		recorder.RecordUpdate(clusterSecret, updatedClusterSecret)
+	}
+	c.warningEventf(EventVerbosityWarning, updatedClusterSecret, "RollbackFailed", "Error rolling back clustersecret %s: %s", clusterSecret.Name, rollbackErr)
+	return updatedClusterSecret, nil
+}
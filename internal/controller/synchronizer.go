@@ -16,6 +16,7 @@ type Synchronizer interface {
 	WaitUntilSynced()
 }
 
+// note: secrets are written in parallel, so the methods of recorders may be called concurrently
 type Recorder interface {
 	RecordCreation(runtime.Object)
 	RecordUpdate(runtime.Object, runtime.Object)
@@ -0,0 +1,34 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sap/clustersecret-operator/internal/tracing"
+)
+
+// start the span of a reconciliation of the given workqueue item; note that the span starts a new trace, since the trace of the admission request
+// causing the change is deliberately not persisted on the clustersecret; the two can be correlated through the logged trace ids
+func (c *Controller) startReconcileSpan(ctx context.Context, item workqueueItem, worker int, reconcileID string) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{
+		tracing.AttributeKeyWorker.Int(worker),
+		tracing.AttributeKeyReconcileID.String(reconcileID),
+		tracing.AttributeKeyRequeues.Int(c.workqueue.NumRequeues(item)),
+	}
+	switch item.key {
+	case workqueueItemKeyNamespace:
+		attributes = append(attributes, tracing.AttributeKeyNamespace.String(item.name))
+	case workqueueItemKeyClusterSecret:
+		attributes = append(attributes, tracing.AttributeKeyClusterSecret.String(item.name))
+	default:
+		panic("this cannot happen")
+	}
+	return tracing.Tracer().Start(ctx, "reconcile "+item.kind(), trace.WithAttributes(attributes...))
+}
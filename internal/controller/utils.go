@@ -7,24 +7,35 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
+	"github.com/sap/clustersecret-operator/internal/common"
+	"github.com/sap/clustersecret-operator/internal/templating"
 	conversionutils "github.com/sap/clustersecret-operator/internal/utils/conversion"
 	stringutils "github.com/sap/clustersecret-operator/internal/utils/strings"
 
 	corev1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 )
 
-func (c *Controller) setClusterSecretFinalizer(clusterSecret *corev1alpha1.ClusterSecret) error {
-	if stringutils.ContainsString(clusterSecret.Finalizers, ControllerName) {
+func (c *Controller) setClusterSecretFinalizer(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret) error {
+	if stringutils.ContainsString(clusterSecret.Finalizers, common.ControllerName) {
 		return nil
 	}
 	newClusterSecret := clusterSecret.DeepCopy()
-	newClusterSecret.Finalizers = append(newClusterSecret.Finalizers, ControllerName)
-	updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Update(context.TODO(), newClusterSecret, metav1.UpdateOptions{FieldManager: ControllerName})
+	newClusterSecret.Finalizers = append(newClusterSecret.Finalizers, common.ControllerName)
+	updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Update(ctx, newClusterSecret, metav1.UpdateOptions{FieldManager: common.ControllerName})
 	if err != nil {
 		return err
 	}
@@ -35,13 +46,13 @@ func (c *Controller) setClusterSecretFinalizer(clusterSecret *corev1alpha1.Clust
 	return nil
 }
 
-func (c *Controller) unsetClusterSecretFinalizer(clusterSecret *corev1alpha1.ClusterSecret) error {
-	if !stringutils.ContainsString(clusterSecret.Finalizers, ControllerName) {
+func (c *Controller) unsetClusterSecretFinalizer(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret) error {
+	if !stringutils.ContainsString(clusterSecret.Finalizers, common.ControllerName) {
 		return nil
 	}
 	newClusterSecret := clusterSecret.DeepCopy()
-	newClusterSecret.Finalizers = stringutils.RemoveString(newClusterSecret.Finalizers, ControllerName)
-	updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Update(context.TODO(), newClusterSecret, metav1.UpdateOptions{FieldManager: ControllerName})
+	newClusterSecret.Finalizers = stringutils.RemoveString(newClusterSecret.Finalizers, common.ControllerName)
+	updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().Update(ctx, newClusterSecret, metav1.UpdateOptions{FieldManager: common.ControllerName})
 	if err != nil {
 		return err
 	}
@@ -52,50 +63,73 @@ func (c *Controller) unsetClusterSecretFinalizer(clusterSecret *corev1alpha1.Clu
 	return nil
 }
 
-func (c *Controller) updateClusterSecretStatus(clusterSecret *corev1alpha1.ClusterSecret, state string) error {
+func (c *Controller) updateClusterSecretStatus(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret, state string, message string) error {
+	return c.updateClusterSecretStatusWithReason(ctx, clusterSecret, state, "ClusterSecret"+state, message)
+}
+
+func (c *Controller) updateClusterSecretStatusWithReason(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret, state string, reason string, message string) error {
 	// return immediately if status is already up-to-date
 	if clusterSecret.Status.ObservedGeneration == clusterSecret.Generation && clusterSecret.Status.State == state {
-		return nil
+		if readyCondition := getReadyCondition(clusterSecret); readyCondition != nil && readyCondition.Reason == reason && readyCondition.Message == truncateConditionMessage(message) {
+			return nil
+		}
 	}
 
-	// store current time for consistent later use
-	now := metav1.Now()
+	// prepare new clustersecret (with new status)
+	// note: other status fields (such as the distribution status) are maintained separately, and therefore retained
+	newClusterSecret := clusterSecret.DeepCopy()
+	newClusterSecret.Status.ObservedGeneration = newClusterSecret.Generation
+	newClusterSecret.Status.State = state
 
-	// build new ready condition
-	var readyCondition corev1alpha1.ClusterSecretCondition
-	for _, cond := range clusterSecret.Status.Conditions {
-		if cond.Type == corev1alpha1.ClusterSecretConditionTypeReady {
-			readyCondition = cond
-			break
-		}
-	}
-	newReadyCondition := corev1alpha1.ClusterSecretCondition{
-		Type: corev1alpha1.ClusterSecretConditionTypeReady,
+	// set ready condition
+	readyCondition := corev1alpha1.ClusterSecretCondition{
+		Type:    corev1alpha1.ClusterSecretConditionTypeReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
 	}
 	if state == corev1alpha1.StateReady {
-		newReadyCondition.Status = corev1.ConditionTrue
-	} else {
-		newReadyCondition.Status = corev1.ConditionFalse
+		readyCondition.Status = corev1.ConditionTrue
 	}
-	newReadyCondition.LastUpdateTime = now
-	if newReadyCondition.Status == readyCondition.Status {
-		newReadyCondition.LastTransitionTime = readyCondition.LastTransitionTime
-	} else {
-		newReadyCondition.LastTransitionTime = now
+	setClusterSecretCondition(newClusterSecret, readyCondition)
+
+	// while processing, the secrets are (potentially) not in sync; the final sync state is set along with the distribution status
+	if state == corev1alpha1.StateProcessing {
+		setClusterSecretCondition(newClusterSecret, corev1alpha1.ClusterSecretCondition{
+			Type:   corev1alpha1.ClusterSecretConditionTypeSynced,
+			Status: corev1.ConditionFalse,
+			Reason: "Progressing",
+		})
 	}
-	newReadyCondition.Reason = "ClusterSecret" + state
-	newReadyCondition.Message = ""
 
-	// prepare new clustersecret (with new status)
+	return c.writeClusterSecretStatus(ctx, clusterSecret, newClusterSecret)
+}
+
+func (c *Controller) updateClusterSecretDistributionStatus(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret, distributionStatus *corev1alpha1.ClusterSecretStatus) error {
+	// prepare new clustersecret (with new status); note: only the distribution related status fields and conditions are taken from distributionStatus
 	newClusterSecret := clusterSecret.DeepCopy()
-	newClusterSecret.Status = corev1alpha1.ClusterSecretStatus{
-		ObservedGeneration: newClusterSecret.Generation,
-		State:              state,
-		Conditions:         []corev1alpha1.ClusterSecretCondition{newReadyCondition},
+	newClusterSecret.Status.MatchedNamespaces = distributionStatus.MatchedNamespaces
+	newClusterSecret.Status.SyncedNamespaces = distributionStatus.SyncedNamespaces
+	newClusterSecret.Status.FailedNamespaces = distributionStatus.FailedNamespaces
+	newClusterSecret.Status.Failures = distributionStatus.Failures
+	newClusterSecret.Status.ConflictingNamespaceCount = distributionStatus.ConflictingNamespaceCount
+	newClusterSecret.Status.ConflictingNamespaces = distributionStatus.ConflictingNamespaces
+	for _, condition := range distributionStatus.Conditions {
+		setClusterSecretCondition(newClusterSecret, condition)
+	}
+
+	return c.writeClusterSecretStatus(ctx, clusterSecret, newClusterSecret)
+}
+
+// write status of newClusterSecret (if it differs from the status of clusterSecret); clusterSecret is updated with the result
+func (c *Controller) writeClusterSecretStatus(ctx context.Context, clusterSecret *corev1alpha1.ClusterSecret, newClusterSecret *corev1alpha1.ClusterSecret) error {
+	// return immediately if status is already up-to-date
+	if equality.Semantic.DeepEqual(newClusterSecret.Status, clusterSecret.Status) {
+		return nil
 	}
 
 	// update status
-	updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().UpdateStatus(context.TODO(), newClusterSecret, metav1.UpdateOptions{FieldManager: ControllerName})
+	updatedClusterSecret, err := c.coreclient.CoreV1alpha1().ClusterSecrets().UpdateStatus(ctx, newClusterSecret, metav1.UpdateOptions{FieldManager: common.ControllerName})
 	if err != nil {
 		return err
 	}
@@ -106,34 +140,464 @@ func (c *Controller) updateClusterSecretStatus(clusterSecret *corev1alpha1.Clust
 	return nil
 }
 
+// set (add or replace) the specified condition in the status of the clustersecret (in memory only);
+// timestamps, the observed generation and message truncation are handled here; if nothing changes, the existing condition is left untouched
+func setClusterSecretCondition(clusterSecret *corev1alpha1.ClusterSecret, condition corev1alpha1.ClusterSecretCondition) {
+	now := metav1.Now()
+	condition.ObservedGeneration = clusterSecret.Generation
+	condition.Message = truncateConditionMessage(condition.Message)
+	condition.LastUpdateTime = now
+	condition.LastTransitionTime = now
+	for i, cond := range clusterSecret.Status.Conditions {
+		if cond.Type != condition.Type {
+			continue
+		}
+		if cond.Status == condition.Status && cond.Reason == condition.Reason && cond.Message == condition.Message && cond.ObservedGeneration == condition.ObservedGeneration {
+			return
+		}
+		if cond.Status == condition.Status {
+			condition.LastTransitionTime = cond.LastTransitionTime
+		}
+		clusterSecret.Status.Conditions[i] = condition
+		return
+	}
+	clusterSecret.Status.Conditions = append(clusterSecret.Status.Conditions, condition)
+}
+
+func getClusterSecretCondition(clusterSecret *corev1alpha1.ClusterSecret, conditionType corev1alpha1.ClusterSecretConditionType) *corev1alpha1.ClusterSecretCondition {
+	for i, cond := range clusterSecret.Status.Conditions {
+		if cond.Type == conditionType {
+			return &clusterSecret.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func getReadyCondition(clusterSecret *corev1alpha1.ClusterSecret) *corev1alpha1.ClusterSecretCondition {
+	return getClusterSecretCondition(clusterSecret, corev1alpha1.ClusterSecretConditionTypeReady)
+}
+
+// truncate condition messages to a sane length (without breaking multi-byte characters)
+func truncateConditionMessage(message string) string {
+	if len(message) <= maxConditionMessageLength {
+		return message
+	}
+	const suffix = " ... (truncated)"
+	n := maxConditionMessageLength - len(suffix)
+	for n > 0 && !utf8.RuneStart(message[n]) {
+		n--
+	}
+	return message[:n] + suffix
+}
+
+// get namespaces selected by clustersecret (by label selector and by name), skipping namespaces in deletion;
+// if a managed secret exists in a skipped namespace, it will be deleted by the reconciliation (which is not necessary, but does not harm)
+func (c *Controller) getSelectedNamespaces(clusterSecret *corev1alpha1.ClusterSecret) ([]*corev1.Namespace, error) {
+	matchingNamespaces, err := c.namespaceLister.List(buildNamespaceSelectorFromClusterSecret(clusterSecret))
+	if err != nil {
+		return nil, err
+	}
+	var selectedNamespaces []*corev1.Namespace
+	for _, namespace := range matchingNamespaces {
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if !namespaceNameMatchesClusterSecret(namespace.Name, clusterSecret) {
+			continue
+		}
+		selectedNamespaces = append(selectedNamespaces, namespace)
+	}
+	return selectedNamespaces, nil
+}
+
+// get all clustersecrets selecting the specified namespace; only the clustersecrets indexed by one of the label keys of the namespace
+// (or not requiring any label key) are evaluated, instead of all clustersecrets
+func (c *Controller) getClusterSecretsSelectingNamespace(namespace *corev1.Namespace) ([]*corev1alpha1.ClusterSecret, error) {
+	keys := []string{namespaceLabelKeyNone}
+	for key := range namespace.Labels {
+		keys = append(keys, key)
+	}
+	var clusterSecrets []*corev1alpha1.ClusterSecret
+	for _, key := range keys {
+		objs, err := c.clusterSecretInformer.GetIndexer().ByIndex(indexNamespaceLabelKey, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			clusterSecret, ok := obj.(*corev1alpha1.ClusterSecret)
+			if !ok {
+				panic("this cannot happen")
+			}
+			if namespaceMatchesClusterSecret(namespace, clusterSecret) {
+				clusterSecrets = append(clusterSecrets, clusterSecret)
+			}
+		}
+	}
+	return clusterSecrets, nil
+}
+
+// get all secrets managed by the specified clustersecret (in all namespaces)
+func (c *Controller) getManagedSecrets(clusterSecretName string) ([]*corev1.Secret, error) {
+	objs, err := c.secretInformer.GetIndexer().ByIndex(indexOwner, clusterSecretName)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]*corev1.Secret, len(objs))
+	for i, obj := range objs {
+		secret, ok := obj.(*corev1.Secret)
+		if !ok {
+			panic("this cannot happen")
+		}
+		secrets[i] = secret
+	}
+	return secrets, nil
+}
+
+// check if the specified namespace is selected by the clustersecret (by both the namespace selector and the namespace names)
+func namespaceMatchesClusterSecret(namespace *corev1.Namespace, clusterSecret *corev1alpha1.ClusterSecret) bool {
+	return buildNamespaceSelectorFromClusterSecret(clusterSecret).Matches(labels.Set(namespace.Labels)) && namespaceNameMatchesClusterSecret(namespace.Name, clusterSecret)
+}
+
+// check if the specified namespace name is selected by the include/exclude lists of the clustersecret
+func namespaceNameMatchesClusterSecret(namespaceName string, clusterSecret *corev1alpha1.ClusterSecret) bool {
+	namespaces := clusterSecret.Spec.Namespaces
+	if namespaces == nil {
+		return true
+	}
+	if len(namespaces.Include) > 0 && !common.NamespaceNameMatchesAny(namespaceName, namespaces.Include) {
+		return false
+	}
+	return !common.NamespaceNameMatchesAny(namespaceName, namespaces.Exclude)
+}
+
 func buildNamespaceSelectorFromClusterSecret(clusterSecret *corev1alpha1.ClusterSecret) labels.Selector {
-	if clusterSecret.Spec.NamespaceSelector == nil {
+	return buildNamespaceSelector(clusterSecret.Spec.NamespaceSelector)
+}
+
+func buildNamespaceSelector(selector *metav1.LabelSelector) labels.Selector {
+	if selector == nil {
 		return labels.Everything()
 	}
-	namespaceSelector, err := metav1.LabelSelectorAsSelector(clusterSecret.Spec.NamespaceSelector)
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
 		panic("this cannot happen")
 	}
 	return namespaceSelector
 }
 
-func buildSecretFromClusterSecret(namespace string, clusterSecret *corev1alpha1.ClusterSecret) *corev1.Secret {
-	return &corev1.Secret{
+// build the distribution related status fields from the selected namespaces, the per-namespace failures, and the conflicting namespaces;
+// a namespace is considered synced if it is selected, and neither failed nor conflicting; the failed namespaces (counter and list) only comprise
+// selected namespaces, whereas failures in other namespaces (e.g. when deleting secrets from namespaces which are no longer selected) are only
+// reflected by the Degraded condition
+func buildDistributionStatus(matchedNamespaces []string, failures map[string]string, conflictingNamespaces []string, message string) *corev1alpha1.ClusterSecretStatus {
+	status := &corev1alpha1.ClusterSecretStatus{
+		MatchedNamespaces:         int32(len(matchedNamespaces)),
+		ConflictingNamespaceCount: int32(len(conflictingNamespaces)),
+	}
+	if len(conflictingNamespaces) > 0 {
+		status.ConflictingNamespaces = append([]string(nil), conflictingNamespaces...)
+		sort.Strings(status.ConflictingNamespaces)
+		if len(status.ConflictingNamespaces) > maxStatusConflictingNamespaces {
+			status.ConflictingNamespaces = status.ConflictingNamespaces[:maxStatusConflictingNamespaces]
+		}
+	}
+	for _, namespace := range matchedNamespaces {
+		if failure, ok := failures[namespace]; ok {
+			status.FailedNamespaces++
+			status.Failures = append(status.Failures, corev1alpha1.NamespaceFailure{Namespace: namespace, Message: truncateConditionMessage(failure)})
+			continue
+		}
+		if stringutils.ContainsString(conflictingNamespaces, namespace) {
+			continue
+		}
+		status.SyncedNamespaces++
+	}
+	sort.Slice(status.Failures, func(i, j int) bool { return status.Failures[i].Namespace < status.Failures[j].Namespace })
+	if len(status.Failures) > maxStatusFailures {
+		status.Failures = status.Failures[:maxStatusFailures]
+	}
+
+	// the Synced condition tells whether all selected namespaces contain an up-to-date secret
+	syncedCondition := corev1alpha1.ClusterSecretCondition{Type: corev1alpha1.ClusterSecretConditionTypeSynced}
+	switch {
+	case status.FailedNamespaces > 0:
+		syncedCondition.Status = corev1.ConditionFalse
+		syncedCondition.Reason = "Failed"
+		syncedCondition.Message = message
+	case status.SyncedNamespaces < status.MatchedNamespaces:
+		syncedCondition.Status = corev1.ConditionFalse
+		syncedCondition.Reason = "Skipped"
+		syncedCondition.Message = fmt.Sprintf("%d namespace(s) skipped because of conflicts", status.MatchedNamespaces-status.SyncedNamespaces)
+	default:
+		syncedCondition.Status = corev1.ConditionTrue
+		syncedCondition.Reason = "Synced"
+	}
+
+	// the Degraded condition tells whether reconciling failed in some (PartiallyFailed) or all (Failed) namespaces
+	degradedCondition := corev1alpha1.ClusterSecretCondition{Type: corev1alpha1.ClusterSecretConditionTypeDegraded}
+	switch {
+	case len(failures) > 0 && status.SyncedNamespaces > 0:
+		degradedCondition.Status = corev1.ConditionTrue
+		degradedCondition.Reason = "PartiallyFailed"
+		degradedCondition.Message = message
+	case len(failures) > 0:
+		degradedCondition.Status = corev1.ConditionTrue
+		degradedCondition.Reason = "Failed"
+		degradedCondition.Message = message
+	default:
+		degradedCondition.Status = corev1.ConditionFalse
+		degradedCondition.Reason = "NoFailures"
+	}
+
+	// the Conflict condition tells whether unmanaged secrets exist in any of the selected namespaces
+	conflictCondition := corev1alpha1.ClusterSecretCondition{Type: corev1alpha1.ClusterSecretConditionTypeConflict}
+	if len(conflictingNamespaces) > 0 {
+		conflictCondition.Status = corev1.ConditionTrue
+		conflictCondition.Reason = "SecretConflict"
+		conflictCondition.Message = fmt.Sprintf("unmanaged secrets exist in %d namespace(s): %s", len(conflictingNamespaces), strings.Join(listConflictingNamespaces(conflictingNamespaces), ", "))
+	} else {
+		conflictCondition.Status = corev1.ConditionFalse
+		conflictCondition.Reason = "NoConflicts"
+	}
+
+	status.Conditions = []corev1alpha1.ClusterSecretCondition{syncedCondition, degradedCondition, conflictCondition}
+	return status
+}
+
+// return the (sorted) conflicting namespaces as listed in messages; lists exceeding maxStatusConflictingNamespaces are truncated, and terminated by "..."
+func listConflictingNamespaces(conflictingNamespaces []string) []string {
+	listedNamespaces := append([]string(nil), conflictingNamespaces...)
+	sort.Strings(listedNamespaces)
+	if len(listedNamespaces) > maxStatusConflictingNamespaces {
+		listedNamespaces = append(listedNamespaces[:maxStatusConflictingNamespaces], "...")
+	}
+	return listedNamespaces
+}
+
+// return the effective deletion policy of the specified clustersecret; a nil clustersecret (that is, an already deleted one) is treated as having the default policy
+func getDeletionPolicy(clusterSecret *corev1alpha1.ClusterSecret) corev1alpha1.DeletionPolicy {
+	if clusterSecret == nil || clusterSecret.Spec.DeletionPolicy == "" {
+		return corev1alpha1.DeletionPolicyDelete
+	}
+	return clusterSecret.Spec.DeletionPolicy
+}
+
+// return the effective conflict policy of the specified clustersecret
+func getConflictPolicy(clusterSecret *corev1alpha1.ClusterSecret) corev1alpha1.ConflictPolicy {
+	if clusterSecret.Spec.ConflictPolicy == "" {
+		return corev1alpha1.ConflictPolicyFail
+	}
+	return clusterSecret.Spec.ConflictPolicy
+}
+
+// build an orphaned copy of the specified secret, i.e. remove all labels and annotations managed by the controller
+func buildOrphanedSecret(secret *corev1.Secret) *corev1.Secret {
+	orphanedSecret := secret.DeepCopy()
+	for key := range orphanedSecret.Labels {
+		if strings.HasPrefix(key, common.ReservedKeyPrefix) {
+			delete(orphanedSecret.Labels, key)
+		}
+	}
+	for key := range orphanedSecret.Annotations {
+		if strings.HasPrefix(key, common.ReservedKeyPrefix) {
+			delete(orphanedSecret.Annotations, key)
+		}
+	}
+	if len(orphanedSecret.Labels) == 0 {
+		orphanedSecret.Labels = nil
+	}
+	if len(orphanedSecret.Annotations) == 0 {
+		orphanedSecret.Annotations = nil
+	}
+	return orphanedSecret
+}
+
+// build secret data from the specified source secret; if keys is empty, all keys will be copied
+func buildDataFromSourceSecret(sourceSecret *corev1.Secret, keys []string) (map[string][]byte, error) {
+	if len(keys) == 0 {
+		return sourceSecret.Data, nil
+	}
+	data := make(map[string][]byte)
+	for _, key := range keys {
+		value, ok := sourceSecret.Data[key]
+		if !ok {
+			return nil, fmt.Errorf("key %s not found in source secret %s/%s", key, sourceSecret.Namespace, sourceSecret.Name)
+		}
+		data[key] = value
+	}
+	return data, nil
+}
+
+// build wanted secret for the specified namespace; data is either the template data, or the data copied from the source secret
+func buildSecretFromClusterSecret(namespace *corev1.Namespace, clusterSecret *corev1alpha1.ClusterSecret, data map[string][]byte) (*corev1.Secret, error) {
+	// merge data of all overrides matching the namespace (later overrides take precedence)
+	if len(clusterSecret.Spec.Overrides) > 0 {
+		baseData := data
+		data = make(map[string][]byte)
+		for key, value := range baseData {
+			data[key] = value
+		}
+		for _, override := range clusterSecret.Spec.Overrides {
+			if buildNamespaceSelector(override.NamespaceSelector).Matches(labels.Set(namespace.Labels)) {
+				for key, value := range override.Data {
+					data[key] = value
+				}
+			}
+		}
+	}
+	if clusterSecret.Spec.Template.Templated {
+		values := templating.NewValues(namespace, clusterSecret.Name)
+		templateData := data
+		data = make(map[string][]byte)
+		for key, value := range templateData {
+			renderedValue, err := templating.Render(key, string(value), values)
+			if err != nil {
+				return nil, err
+			}
+			data[key] = renderedValue
+		}
+	}
+	labels := make(map[string]string)
+	annotations := make(map[string]string)
+	if metadata := clusterSecret.Spec.Template.Metadata; metadata != nil {
+		for key, value := range metadata.Labels {
+			labels[key] = value
+		}
+		for key, value := range metadata.Annotations {
+			annotations[key] = value
+		}
+	}
+	labels[common.LabelKeyName] = clusterSecret.Name
+	annotations[common.AnnotationKeyGeneration] = conversionutils.Itoa(clusterSecret.Generation)
+	var immutable *bool
+	if clusterSecret.Spec.Template.Immutable {
+		immutable = &[]bool{true}[0]
+	}
+	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Secret",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-			Name:      clusterSecret.Name,
-			Labels: map[string]string{
-				LabelKeyName: clusterSecret.Name,
-			},
-			Annotations: map[string]string{
-				AnnotationKeyGeneration: conversionutils.Itoa(clusterSecret.Generation),
-			},
+			Namespace:   namespace.Name,
+			Name:        common.GetSecretName(clusterSecret),
+			Labels:      labels,
+			Annotations: annotations,
 		},
-		Type: clusterSecret.Spec.Template.Type,
-		Data: clusterSecret.Spec.Template.Data,
+		Type:      clusterSecret.Spec.Template.Type,
+		Data:      data,
+		Immutable: immutable,
+	}
+	hash, err := computeSecretContentHash(secret)
+	if err != nil {
+		return nil, err
+	}
+	annotations[common.AnnotationKeyContentHash] = hash
+	return secret, nil
+}
+
+// compute a deterministic hash of the content of the given secret, that is of its type, immutable flag, data, labels and annotations;
+// the generation and content hash annotations are not considered, so the hash does not change if the clustersecret changes in other aspects
+// (such as the namespace selector)
+func computeSecretContentHash(secret *corev1.Secret) (string, error) {
+	annotations := make(map[string]string)
+	for key, value := range secret.Annotations {
+		if key != common.AnnotationKeyGeneration && key != common.AnnotationKeyContentHash {
+			annotations[key] = value
+		}
+	}
+	// note: maps are marshalled with sorted keys, so the result is deterministic
+	raw, err := json.Marshal(struct {
+		Type        corev1.SecretType `json:"type"`
+		Immutable   bool              `json:"immutable"`
+		Data        map[string][]byte `json:"data"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	}{
+		Type:        secret.Type,
+		Immutable:   isSecretImmutable(secret),
+		Data:        secret.Data,
+		Labels:      secret.Labels,
+		Annotations: annotations,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// check if existing secret is up-to-date with respect to the wanted secret
+// note: the generation of the clustersecret is not considered, such that changes of the clustersecret which do not affect the rendered secret
+// (e.g. of the namespace selector) do not cause any secret writes; as a consequence, the generation annotation just reflects the generation
+// of the clustersecret which last wrote the secret; the content hash annotation is compared as part of the annotations, and existing secrets
+// not having it yet (e.g. written by an older version of the controller) are therefore updated once
+func isSecretUpToDate(existingSecret *corev1.Secret, secret *corev1.Secret) bool {
+	return len(getSecretDifferences(existingSecret, secret)) == 0
+}
+
+// return the aspects (type, immutable, data, labels, annotations) in which the existing secret differs from the wanted secret;
+// labels and annotations which are not set on the wanted secret are ignored; the generation annotation just has to be a valid number
+func getSecretDifferences(existingSecret *corev1.Secret, secret *corev1.Secret) []string {
+	var differences []string
+	if existingSecret.Type != secret.Type {
+		differences = append(differences, "type")
+	}
+	if isSecretImmutable(existingSecret) != isSecretImmutable(secret) {
+		differences = append(differences, "immutable")
+	}
+	if !equality.Semantic.DeepEqual(existingSecret.Data, secret.Data) {
+		differences = append(differences, "data")
+	}
+	for key, value := range secret.Labels {
+		if existingValue, ok := existingSecret.Labels[key]; !ok || existingValue != value {
+			differences = append(differences, "labels")
+			break
+		}
+	}
+	for key, value := range secret.Annotations {
+		if key == common.AnnotationKeyGeneration {
+			// note: the generation annotation is missing on secrets which are about to be adopted, and may be invalid on secrets modified by someone else
+			if _, err := strconv.ParseInt(existingSecret.Annotations[key], 10, 64); err != nil {
+				differences = append(differences, "annotations")
+				break
+			}
+			continue
+		}
+		if existingValue, ok := existingSecret.Annotations[key]; !ok || existingValue != value {
+			differences = append(differences, "annotations")
+			break
+		}
+	}
+	return differences
+}
+
+// check if existing secret cannot be updated to the wanted secret, but has to be deleted and recreated;
+// this is the case if the type changes, or if the existing secret is immutable, and data or the immutable flag change
+func isSecretRecreationNeeded(existingSecret *corev1.Secret, secret *corev1.Secret) bool {
+	if existingSecret.Type != secret.Type {
+		return true
+	}
+	if isSecretImmutable(existingSecret) {
+		return !isSecretImmutable(secret) || !equality.Semantic.DeepEqual(existingSecret.Data, secret.Data)
+	}
+	return false
+}
+
+func isSecretImmutable(secret *corev1.Secret) bool {
+	return secret.Immutable != nil && *secret.Immutable
+}
+
+// get the given source secret; since only the metadata of unmanaged secrets is cached, the secret is read from the API server
+func (c *Controller) getSourceSecret(ctx context.Context, namespace string, name string) (*corev1.Secret, error) {
+	return c.kubeclient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// informer transform function, removing managed fields from the given object before it is added to the cache
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	// note: the transform function may be called with tombstones (cache.DeletedFinalStateUnknown), which are passed through
+	if accessor, ok := obj.(metav1.Object); ok {
+		accessor.SetManagedFields(nil)
 	}
+	return obj, nil
 }
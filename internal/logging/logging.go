@@ -0,0 +1,55 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// klog's default text format (key=value pairs for structured log entries)
+	FormatText = "text"
+	// one JSON object per log entry
+	FormatJSON = "json"
+)
+
+// Configure the output format of klog; must be called after flags are parsed, and before anything is logged;
+// note that verbosity (-v) and other klog flags still apply
+func SetFormat(format string) error {
+	switch format {
+	case FormatText:
+		// nothing to do
+	case FormatJSON:
+		// verbosity is already enforced by klog (which passes V(n) messages with slog level -n to the handler),
+		// so the handler must not filter, and reports all non-error messages as INFO
+		handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.Level(math.MinInt),
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if level, ok := a.Value.Any().(slog.Level); ok && a.Key == slog.LevelKey && len(groups) == 0 && level < slog.LevelInfo {
+					a.Value = slog.StringValue(slog.LevelInfo.String())
+				}
+				return a
+			},
+		})
+		klog.SetLogger(logr.FromSlogHandler(handler))
+	default:
+		return fmt.Errorf("invalid log format %s (must be one of %s, %s)", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// Log an error (structured, like klog.ErrorS) and exit
+func Fatal(err error, msg string, keysAndValues ...any) {
+	klog.ErrorSDepth(1, err, msg, keysAndValues...)
+	klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+}
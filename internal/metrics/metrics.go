@@ -0,0 +1,250 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"k8s.io/client-go/tools/leaderelection"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	namespace = "clustersecret_operator"
+)
+
+// Registry holds all metrics exposed by the controller
+var Registry = prometheus.NewRegistry()
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reconcile_total",
+			Help:      "Total number of reconciliations per type (namespace, clustersecret) and result (success, error)",
+		},
+		[]string{"type", "result"},
+	)
+	reconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "reconcile_duration_seconds",
+			Help:      "Duration of reconciliations per type (namespace, clustersecret)",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		},
+		[]string{"type"},
+	)
+	apiWriteErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_write_errors_total",
+			Help:      "Total number of failed write requests to the Kubernetes API server per verb",
+		},
+		[]string{"verb"},
+	)
+	leaderElectionMaster = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "leader_election_master_status",
+			Help:      "Whether this instance is the leader (1) or not (0) for the given lease",
+		},
+		[]string{"name"},
+	)
+	leaderElectionSlowpath = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "leader_election_slowpath_total",
+			Help:      "Total number of slow path exercised in renewing leader leases",
+		},
+		[]string{"name"},
+	)
+)
+
+var (
+	workqueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "workqueue",
+			Name:      "depth",
+			Help:      "Current depth of workqueue",
+		},
+		[]string{"name"},
+	)
+	workqueueAdds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "workqueue",
+			Name:      "adds_total",
+			Help:      "Total number of adds handled by workqueue",
+		},
+		[]string{"name"},
+	)
+	workqueueLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "workqueue",
+			Name:      "queue_duration_seconds",
+			Help:      "How long in seconds an item stays in workqueue before being requested",
+			Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 12),
+		},
+		[]string{"name"},
+	)
+	workqueueWorkDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "workqueue",
+			Name:      "work_duration_seconds",
+			Help:      "How long in seconds processing an item from workqueue takes",
+			Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 12),
+		},
+		[]string{"name"},
+	)
+	workqueueUnfinishedWork = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "workqueue",
+			Name:      "unfinished_work_seconds",
+			Help:      "How many seconds of work has been done that is in progress and hasn't been observed by work_duration",
+		},
+		[]string{"name"},
+	)
+	workqueueLongestRunningProcessor = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "workqueue",
+			Name:      "longest_running_processor_seconds",
+			Help:      "How many seconds has the longest running processor for workqueue been running",
+		},
+		[]string{"name"},
+	)
+	workqueueRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "workqueue",
+			Name:      "retries_total",
+			Help:      "Total number of retries handled by workqueue",
+		},
+		[]string{"name"},
+	)
+)
+
+// Setup metrics; that is, register all metrics with Registry, and install the according workqueue, leader election and client-go
+// metric providers; must be called at most once, and before any workqueue, client or leader elector is created; if not called,
+// the metrics of this package are still maintained (as far as not fed by the providers), but not exposed
+func Setup() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		reconcileTotal,
+		reconcileDuration,
+		apiWriteErrorsTotal,
+		leaderElectionMaster,
+		leaderElectionSlowpath,
+		workqueueDepth,
+		workqueueAdds,
+		workqueueLatency,
+		workqueueWorkDuration,
+		workqueueUnfinishedWork,
+		workqueueLongestRunningProcessor,
+		workqueueRetries,
+	)
+	// note: all of these are one-shot (first caller wins), so they must happen before any queue, client or leader elector is created
+	workqueue.SetProvider(workqueueMetricsProvider{})
+	leaderelection.SetProvider(leaderMetricsProvider{})
+	clientmetrics.Register(clientmetrics.RegisterOpts{RequestResult: requestResultAdapter{}})
+}
+
+// ObserveReconcile records the outcome and duration of a reconciliation of the given type
+func ObserveReconcile(kind string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileTotal.WithLabelValues(kind, result).Inc()
+	reconcileDuration.WithLabelValues(kind).Observe(duration.Seconds())
+}
+
+// workqueue metrics provider (see k8s.io/client-go/util/workqueue)
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
+
+// leader election metrics provider (see k8s.io/client-go/tools/leaderelection)
+type leaderMetricsProvider struct{}
+
+func (leaderMetricsProvider) NewLeaderMetric() leaderelection.LeaderMetric {
+	return leaderMetric{}
+}
+
+type leaderMetric struct{}
+
+func (leaderMetric) On(name string) {
+	leaderElectionMaster.WithLabelValues(name).Set(1)
+}
+
+func (leaderMetric) Off(name string) {
+	leaderElectionMaster.WithLabelValues(name).Set(0)
+}
+
+func (leaderMetric) SlowpathExercised(name string) {
+	leaderElectionSlowpath.WithLabelValues(name).Inc()
+}
+
+// client-go request result adapter (see k8s.io/client-go/tools/metrics); counts failed write requests
+type requestResultAdapter struct{}
+
+func (requestResultAdapter) Increment(ctx context.Context, code string, method string, host string) {
+	var verb string
+	switch strings.ToUpper(method) {
+	case "POST":
+		verb = "create"
+	case "PUT":
+		verb = "update"
+	case "PATCH":
+		verb = "patch"
+	case "DELETE":
+		verb = "delete"
+	default:
+		return
+	}
+	// code is the http status code, or "<error>" if the request did not return a response
+	if strings.HasPrefix(code, "2") {
+		return
+	}
+	apiWriteErrorsTotal.WithLabelValues(verb).Inc()
+}
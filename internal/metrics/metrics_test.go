@@ -0,0 +1,55 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveReconcile(t *testing.T) {
+	ObserveReconcile("clustersecret", time.Millisecond, nil)
+	ObserveReconcile("clustersecret", time.Millisecond, errors.New("some error"))
+	ObserveReconcile("clustersecret", time.Millisecond, errors.New("some error"))
+	if n := testutil.ToFloat64(reconcileTotal.WithLabelValues("clustersecret", "success")); n != 1 {
+		t.Errorf("unexpected number of successful reconciliations; expected: 1, actual: %v", n)
+	}
+	if n := testutil.ToFloat64(reconcileTotal.WithLabelValues("clustersecret", "error")); n != 2 {
+		t.Errorf("unexpected number of failed reconciliations; expected: 2, actual: %v", n)
+	}
+}
+
+func TestRequestResultAdapter(t *testing.T) {
+	adapter := requestResultAdapter{}
+	adapter.Increment(context.TODO(), "200", "GET", "")
+	adapter.Increment(context.TODO(), "500", "GET", "")
+	adapter.Increment(context.TODO(), "201", "POST", "")
+	adapter.Increment(context.TODO(), "409", "PUT", "")
+	adapter.Increment(context.TODO(), "<error>", "DELETE", "")
+	adapter.Increment(context.TODO(), "404", "DELETE", "")
+	expected := map[string]float64{"create": 0, "update": 1, "patch": 0, "delete": 2}
+	for verb, n := range expected {
+		if m := testutil.ToFloat64(apiWriteErrorsTotal.WithLabelValues(verb)); m != n {
+			t.Errorf("unexpected number of write errors for verb %s; expected: %v, actual: %v", verb, n, m)
+		}
+	}
+}
+
+func TestWorkqueueMetricsProvider(t *testing.T) {
+	provider := workqueueMetricsProvider{}
+	provider.NewAddsMetric("my-queue").Inc()
+	provider.NewDepthMetric("my-queue").Inc()
+	if n := testutil.CollectAndCount(workqueueAdds, "clustersecret_operator_workqueue_adds_total"); n != 1 {
+		t.Errorf("unexpected number of workqueue adds metrics; expected: 1, actual: %d", n)
+	}
+	if n := testutil.CollectAndCount(workqueueDepth, "clustersecret_operator_workqueue_depth"); n != 1 {
+		t.Errorf("unexpected number of workqueue depth metrics; expected: 1, actual: %d", n)
+	}
+}
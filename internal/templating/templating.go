@@ -0,0 +1,189 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package templating
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Values available to a template; namespace is the target namespace of the rendered secret
+type Values struct {
+	Namespace     NamespaceValues
+	ClusterSecret ClusterSecretValues
+}
+
+type NamespaceValues struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+type ClusterSecretValues struct {
+	Name string
+}
+
+// build template values for the specified target namespace and clustersecret name
+func NewValues(namespace *corev1.Namespace, clusterSecretName string) *Values {
+	return &Values{
+		Namespace: NamespaceValues{
+			Name:        namespace.Name,
+			Labels:      namespace.Labels,
+			Annotations: namespace.Annotations,
+		},
+		ClusterSecret: ClusterSecretValues{
+			Name: clusterSecretName,
+		},
+	}
+}
+
+// text/template builtins which may be used in templates; other builtins (such as call, html, js, urlquery, slice, println) are rejected
+var allowedBuiltins = map[string]bool{
+	"and":    true,
+	"or":     true,
+	"not":    true,
+	"eq":     true,
+	"ne":     true,
+	"lt":     true,
+	"le":     true,
+	"gt":     true,
+	"ge":     true,
+	"len":    true,
+	"index":  true,
+	"print":  true,
+	"printf": true,
+}
+
+// parse template text (without rendering it); used to validate templates
+func Parse(name string, text string) error {
+	_, err := parseTemplate(name, text, &Values{})
+	return err
+}
+
+// parse and render template text with the specified values
+func Render(name string, text string, values *Values) ([]byte, error) {
+	t, err := parseTemplate(name, text, values)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func parseTemplate(name string, text string, values *Values) (*template.Template, error) {
+	functions := funcMap(values)
+	t, err := template.New(name).Option("missingkey=error").Funcs(functions).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// note: besides t itself, this includes all templates defined within text
+	for _, definedTemplate := range t.Templates() {
+		if definedTemplate.Tree == nil {
+			continue
+		}
+		if err := checkFunctions(definedTemplate.Tree.Root, functions); err != nil {
+			return nil, fmt.Errorf("template: %s: %s", definedTemplate.Name(), err)
+		}
+	}
+	return t, nil
+}
+
+// check that the parse tree below node uses only the functions of the specified function map, or the allowed builtins
+func checkFunctions(node parse.Node, functions template.FuncMap) error {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, child := range node.Nodes {
+			if err := checkFunctions(child, functions); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkFunctions(node.Pipe, functions)
+	case *parse.TemplateNode:
+		return checkFunctions(node.Pipe, functions)
+	case *parse.IfNode:
+		return checkBranchFunctions(&node.BranchNode, functions)
+	case *parse.RangeNode:
+		return checkBranchFunctions(&node.BranchNode, functions)
+	case *parse.WithNode:
+		return checkBranchFunctions(&node.BranchNode, functions)
+	case *parse.PipeNode:
+		if node == nil {
+			return nil
+		}
+		for _, command := range node.Cmds {
+			if err := checkFunctions(command, functions); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range node.Args {
+			if err := checkFunctions(arg, functions); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return checkFunctions(node.Node, functions)
+	case *parse.IdentifierNode:
+		if _, ok := functions[node.Ident]; !ok && !allowedBuiltins[node.Ident] {
+			return fmt.Errorf("function %q not available", node.Ident)
+		}
+	}
+	return nil
+}
+
+func checkBranchFunctions(node *parse.BranchNode, functions template.FuncMap) error {
+	if err := checkFunctions(node.Pipe, functions); err != nil {
+		return err
+	}
+	if err := checkFunctions(node.List, functions); err != nil {
+		return err
+	}
+	return checkFunctions(node.ElseList, functions)
+}
+
+// functions available to templates, in addition to the allowed text/template builtins
+func funcMap(values *Values) template.FuncMap {
+	return template.FuncMap{
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"b64dec": func(s string) (string, error) {
+			raw, err := base64.StdEncoding.DecodeString(s)
+			return string(raw), err
+		},
+		"default": func(def string, s string) string {
+			if s == "" {
+				return def
+			}
+			return s
+		},
+		"lower": func(s string) string {
+			return strings.ToLower(s)
+		},
+		"upper": func(s string) string {
+			return strings.ToUpper(s)
+		},
+		"label": func(key string) string {
+			return values.Namespace.Labels[key]
+		},
+		"annotation": func(key string) string {
+			return values.Namespace.Annotations[key]
+		},
+	}
+}
@@ -0,0 +1,93 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package templating
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// test: parsing and rendering of templates
+func TestRender(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-namespace",
+			Labels:      map[string]string{"stage": "dev"},
+			Annotations: map[string]string{"owner": "Team-A"},
+		},
+	}
+	values := NewValues(namespace, "my-secret")
+
+	tests := []struct {
+		name             string
+		text             string
+		expected         string
+		expectParseError bool
+		expectError      bool
+	}{
+		{name: "plain text", text: "myvalue", expected: "myvalue"},
+		{name: "namespace name", text: "{{ .Namespace.Name }}", expected: "my-namespace"},
+		{name: "namespace label", text: "{{ .Namespace.Labels.stage }}", expected: "dev"},
+		{name: "clustersecret name", text: "{{ .ClusterSecret.Name }}", expected: "my-secret"},
+		{name: "b64enc", text: `{{ b64enc "myvalue" }}`, expected: "bXl2YWx1ZQ=="},
+		{name: "b64dec", text: `{{ b64dec "bXl2YWx1ZQ==" }}`, expected: "myvalue"},
+		{name: "b64dec invalid input", text: `{{ b64dec "not base64" }}`, expectError: true},
+		{name: "default with empty value", text: `{{ label "region" | default "eu" }}`, expected: "eu"},
+		{name: "default with non-empty value", text: `{{ label "stage" | default "prod" }}`, expected: "dev"},
+		{name: "lower", text: `{{ annotation "owner" | lower }}`, expected: "team-a"},
+		{name: "upper", text: `{{ annotation "owner" | upper }}`, expected: "TEAM-A"},
+		{name: "label", text: `{{ label "stage" }}`, expected: "dev"},
+		{name: "missing label", text: `{{ label "region" }}`, expected: ""},
+		{name: "annotation", text: `{{ annotation "owner" }}`, expected: "Team-A"},
+		{name: "missing annotation", text: `{{ annotation "description" }}`, expected: ""},
+		{name: "missing function argument", text: "{{ lower }}", expectError: true},
+		{name: "wrong function argument type", text: "{{ upper .Namespace.Labels }}", expectError: true},
+		{name: "missing map key", text: "{{ .Namespace.Labels.region }}", expectError: true},
+		{name: "missing field", text: "{{ .Namespace.Region }}", expectError: true},
+		{name: "unterminated action", text: "{{ .Namespace.Name", expectParseError: true},
+		{name: "unknown function", text: `{{ env "HOME" }}`, expectParseError: true},
+		{name: "builtin conditional", text: `{{ if eq (label "stage") "dev" }}debug{{ else }}info{{ end }}`, expected: "debug"},
+		{name: "builtin printf", text: `{{ printf "%s.%s" .Namespace.Name "svc" }}`, expected: "my-namespace.svc"},
+		{name: "builtin index", text: `{{ index .Namespace.Labels "stage" }}`, expected: "dev"},
+		{name: "builtin call", text: "{{ call .Namespace.Name }}", expectParseError: true},
+		{name: "builtin println", text: `{{ println "myvalue" }}`, expectParseError: true},
+		{name: "builtin in branch", text: `{{ with .Namespace.Name }}{{ html . }}{{ end }}`, expectParseError: true},
+		{name: "builtin in defined template", text: `{{ define "inner" }}{{ js . }}{{ end }}{{ template "inner" .Namespace.Name }}`, expectParseError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Parse("mykey", test.text)
+			if test.expectParseError {
+				if err == nil {
+					t.Errorf("expected parse error, but parsing succeeded")
+				}
+				if _, err := Render("mykey", test.text, values); err == nil {
+					t.Errorf("expected render error, but rendering succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected parse error: %s", err)
+			}
+			rendered, err := Render("mykey", test.text, values)
+			if test.expectError {
+				if err == nil {
+					t.Errorf("expected render error, but rendering succeeded: %s", rendered)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected render error: %s", err)
+			}
+			if string(rendered) != test.expected {
+				t.Errorf("unexpected rendered value; expected: %s, actual: %s", test.expected, rendered)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	tracerName = "github.com/sap/clustersecret-operator"
+)
+
+// span attribute keys
+const (
+	AttributeKeyClusterSecret = attribute.Key("k8s.clustersecret.name")
+	AttributeKeyNamespace     = attribute.Key("k8s.namespace.name")
+	AttributeKeySecret        = attribute.Key("k8s.secret.name")
+	AttributeKeyOperation     = attribute.Key("clustersecret_operator.operation")
+	AttributeKeyReconcileID   = attribute.Key("clustersecret_operator.reconcile_id")
+	AttributeKeyWorker        = attribute.Key("clustersecret_operator.worker")
+	AttributeKeyRequeues      = attribute.Key("clustersecret_operator.requeues")
+)
+
+// Setup tracing, exporting spans via OTLP (gRPC) to the given endpoint (host:port); the returned function flushes
+// pending spans and shuts down the exporter; if endpoint is empty, tracing remains disabled (all spans are no-ops)
+func Setup(ctx context.Context, serviceName string, endpoint string, insecure bool) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Set tracer provider (and the W3C trace context propagator); Setup calls this, and tests may call it with an in-memory provider
+func SetTracerProvider(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Return the tracer used throughout this module
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Wrap the transport of the given rest config, such that every API request is traced (as child of the span in the request context)
+func WrapConfig(cfg *rest.Config) {
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(rt, otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}))
+	})
+}
+
+// Record error (if not nil) in the given span, and mark the span as failed
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Encode the span context contained in ctx as W3C traceparent (returns the empty string if ctx contains no valid span context)
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// Return the key/value pairs identifying the span contained in ctx, to be attached to log entries (returns nil if the span is not recording,
+// e.g. because tracing is disabled)
+func LogValues(ctx context.Context) []interface{} {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+	return []interface{}{"traceID", span.SpanContext().TraceID().String(), "spanID", span.SpanContext().SpanID().String()}
+}
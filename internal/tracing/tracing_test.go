@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	collectortracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// in-process stand-in for an OTLP collector, recording the names of all received spans
+type collector struct {
+	collectortracev1.UnimplementedTraceServiceServer
+	mutex     sync.Mutex
+	spanNames []string
+}
+
+func (c *collector) Export(ctx context.Context, request *collectortracev1.ExportTraceServiceRequest) (*collectortracev1.ExportTraceServiceResponse, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, resourceSpans := range request.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				c.spanNames = append(c.spanNames, span.Name)
+			}
+		}
+	}
+	return &collectortracev1.ExportTraceServiceResponse{}, nil
+}
+
+// test: export of spans via OTLP
+func TestSetup(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	collector := &collector{}
+	collectortracev1.RegisterTraceServiceServer(server, collector)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	shutdown, err := Setup(context.Background(), "test", listener.Addr().String(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, span := Tracer().Start(context.Background(), "parent")
+	_, child := Tracer().Start(ctx, "child")
+	if traceParent, expectedTraceParent := TraceParent(ctx), "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01"; traceParent != expectedTraceParent {
+		t.Errorf("unexpected trace parent of active span; expected: %s, actual: %s", expectedTraceParent, traceParent)
+	}
+	if values := LogValues(ctx); len(values) != 4 || values[1] != span.SpanContext().TraceID().String() {
+		t.Errorf("unexpected log values of active span: %v", values)
+	}
+	child.End()
+	span.End()
+
+	// note: shutting down flushes all pending spans
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	if len(collector.spanNames) != 2 {
+		t.Errorf("unexpected spans received by collector: %v", collector.spanNames)
+	}
+}
@@ -70,8 +70,69 @@ type ClusterSecretList struct {
 type ClusterSecretSpec struct {
 	// Namespace selector; defines to which namespaces the secrets will be distributed
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Namespace names; further restricts the namespaces selected by the namespace selector, by their names
+	Namespaces *NamespaceNames `json:"namespaces,omitempty"`
+	// Source secret reference; if set, the data of the distributed secrets is copied from the referenced secret
+	// (in that case, the secret template must not contain data)
+	SourceRef *SecretReference `json:"sourceRef,omitempty"`
 	// Secret template; defines how the distributed secrets shall look like
 	Template SecretTemplateSpec `json:"template"`
+	// Data overrides; the data of all overrides matching a namespace is merged (in the specified order) over the template data
+	Overrides []DataOverride `json:"overrides,omitempty"`
+	// Deletion policy; defines what happens to the distributed secrets if the ClusterSecret is deleted,
+	// or if a namespace is no longer selected; one of ('Delete', 'Orphan'), defaults to 'Delete'
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Conflict policy; defines what happens if a secret with the target name already exists in a selected namespace,
+	// but is not managed by the ClusterSecret; one of ('Fail', 'Skip', 'Adopt'), defaults to 'Fail'
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+// DeletionPolicy defines what happens to distributed secrets which are no longer wanted
+type DeletionPolicy string
+
+const (
+	// Delete the secrets
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// Leave the secrets (including their data) in place, but remove the controller's labels and annotations
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// ConflictPolicy defines how existing secrets, which are not managed by the ClusterSecret, are treated
+type ConflictPolicy string
+
+const (
+	// Fail reconciliation (the ClusterSecret goes into error state)
+	ConflictPolicyFail ConflictPolicy = "Fail"
+	// Leave the existing secret untouched, and report the conflict in the ClusterSecret status
+	ConflictPolicySkip ConflictPolicy = "Skip"
+	// Take ownership of the existing secret, and overwrite it (only applies to secrets not managed by any other ClusterSecret)
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+)
+
+// NamespaceNames defines namespaces by their names; entries may be exact names, or glob patterns (such as team-*)
+type NamespaceNames struct {
+	// Namespaces to be included; if empty, all namespaces are included
+	Include []string `json:"include,omitempty"`
+	// Namespaces to be excluded; takes precedence over include
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// DataOverride defines data which overrides the template data in certain namespaces
+type DataOverride struct {
+	// Namespace selector; defines to which namespaces the override applies
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+	// Secret data as base64 encoded raw data
+	Data map[string][]byte `json:"data,omitempty"`
+}
+
+// SecretReference references an existing secret (or some keys of it)
+type SecretReference struct {
+	// Namespace of the referenced secret
+	Namespace string `json:"namespace"`
+	// Name of the referenced secret
+	Name string `json:"name"`
+	// Keys to be copied from the referenced secret; if empty, all keys are copied
+	Keys []string `json:"keys,omitempty"`
 }
 
 // ClusterSecretStatus reflects the actual state of ClusterSecret
@@ -82,16 +143,55 @@ type ClusterSecretStatus struct {
 	State string `json:"state,omitempty"`
 	// State expressed as conditions (for usage with kubectl wait et al.)
 	Conditions []ClusterSecretCondition `json:"conditions,omitempty"`
+	// Number of namespaces selected by the ClusterSecret
+	MatchedNamespaces int32 `json:"matchedNamespaces"`
+	// Number of selected namespaces containing an up-to-date secret
+	SyncedNamespaces int32 `json:"syncedNamespaces"`
+	// Number of namespaces where reconciling the secret failed
+	FailedNamespaces int32 `json:"failedNamespaces"`
+	// Failing namespaces and according error messages (bounded; if there are more failing namespaces than listed here, the list is truncated)
+	Failures []NamespaceFailure `json:"failures,omitempty"`
+	// Number of selected namespaces containing a secret with the target name which is not managed by the ClusterSecret
+	ConflictingNamespaceCount int32 `json:"conflictingNamespaceCount,omitempty"`
+	// Namespaces containing a secret with the target name which is not managed by the ClusterSecret (bounded; if there are more
+	// conflicting namespaces than listed here, the list is truncated)
+	ConflictingNamespaces []string `json:"conflictingNamespaces,omitempty"`
+	// Number of namespaces containing a managed secret which was modified by someone else (as detected by the last audit, if auditing is enabled)
+	DriftedNamespaces int32 `json:"driftedNamespaces,omitempty"`
+}
+
+// NamespaceFailure describes why reconciling the secret in a certain namespace failed
+type NamespaceFailure struct {
+	// Namespace name
+	Namespace string `json:"namespace"`
+	// Error message
+	Message string `json:"message"`
 }
 
 // SecretTemplateSpec defines how the managed secrets should look like
 type SecretTemplateSpec struct {
+	// Secret name; defaults to the name of the ClusterSecret
+	Name string `json:"name,omitempty"`
+	// Secret metadata; labels and annotations to be added to the managed secrets
+	Metadata *SecretTemplateMetadata `json:"metadata,omitempty"`
 	// Secret type
 	Type corev1.SecretType `json:"type"`
 	// Secret data as base64 encoded raw data
 	Data map[string][]byte `json:"data,omitempty"`
 	// Secret data as string
 	StringData map[string]string `json:"stringData,omitempty"`
+	// Whether data values are Go templates, to be rendered individually for each target namespace
+	Templated bool `json:"templated,omitempty"`
+	// Whether the managed secrets are immutable; changes of the data cause the secrets to be deleted and recreated
+	Immutable bool `json:"immutable,omitempty"`
+}
+
+// SecretTemplateMetadata defines labels and annotations of the managed secrets
+type SecretTemplateMetadata struct {
+	// Labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 const (
@@ -105,15 +205,24 @@ const (
 type ClusterSecretConditionType string
 
 const (
+	// All secrets are distributed and up-to-date
 	ClusterSecretConditionTypeReady = "Ready"
+	// Secrets in all selected namespaces are up-to-date
+	ClusterSecretConditionTypeSynced = "Synced"
+	// Reconciling the secrets failed in some or all namespaces
+	ClusterSecretConditionTypeDegraded = "Degraded"
+	// Unmanaged secrets exist (in some of the selected namespaces) which conflict with the managed secrets
+	ClusterSecretConditionTypeConflict = "Conflict"
 )
 
 // Condition represents a certain aspect of the overall state of a ClusterSecret object
 type ClusterSecretCondition struct {
-	// Type of the condition, known values are ('Ready').
+	// Type of the condition, known values are ('Ready', 'Synced', 'Degraded', 'Conflict').
 	Type ClusterSecretConditionType `json:"type"`
 	// Status of the condition, one of ('True', 'False', 'Unknown').
 	Status corev1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the ClusterSecret the condition was set for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// LastUpdateTime is the timestamp corresponding to the last status
 	// update to this condition.
 	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
@@ -18,10 +18,12 @@ import (
 //
 // Condition represents a certain aspect of the overall state of a ClusterSecret object
 type ClusterSecretConditionApplyConfiguration struct {
-	// Type of the condition, known values are ('Ready').
+	// Type of the condition, known values are ('Ready', 'Synced', 'Degraded', 'Conflict').
 	Type *corecssapcomv1alpha1.ClusterSecretConditionType `json:"type,omitempty"`
 	// Status of the condition, one of ('True', 'False', 'Unknown').
 	Status *v1.ConditionStatus `json:"status,omitempty"`
+	// ObservedGeneration is the generation of the ClusterSecret the condition was set for.
+	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
 	// LastUpdateTime is the timestamp corresponding to the last status
 	// update to this condition.
 	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
@@ -58,6 +60,14 @@ func (b *ClusterSecretConditionApplyConfiguration) WithStatus(value v1.Condition
 	return b
 }
 
+// WithObservedGeneration sets the ObservedGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ObservedGeneration field is set to the value of the last call.
+func (b *ClusterSecretConditionApplyConfiguration) WithObservedGeneration(value int64) *ClusterSecretConditionApplyConfiguration {
+	b.ObservedGeneration = &value
+	return b
+}
+
 // WithLastUpdateTime sets the LastUpdateTime field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the LastUpdateTime field is set to the value of the last call.
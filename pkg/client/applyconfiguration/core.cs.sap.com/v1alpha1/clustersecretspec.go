@@ -8,6 +8,7 @@ SPDX-License-Identifier: Apache-2.0
 package v1alpha1
 
 import (
+	corecssapcomv1alpha1 "github.com/sap/clustersecret-operator/pkg/apis/core.cs.sap.com/v1alpha1"
 	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
@@ -18,8 +19,21 @@ import (
 type ClusterSecretSpecApplyConfiguration struct {
 	// Namespace selector; defines to which namespaces the secrets will be distributed
 	NamespaceSelector *v1.LabelSelectorApplyConfiguration `json:"namespaceSelector,omitempty"`
+	// Namespace names; further restricts the namespaces selected by the namespace selector, by their names
+	Namespaces *NamespaceNamesApplyConfiguration `json:"namespaces,omitempty"`
+	// Source secret reference; if set, the data of the distributed secrets is copied from the referenced secret
+	// (in that case, the secret template must not contain data)
+	SourceRef *SecretReferenceApplyConfiguration `json:"sourceRef,omitempty"`
 	// Secret template; defines how the distributed secrets shall look like
 	Template *SecretTemplateSpecApplyConfiguration `json:"template,omitempty"`
+	// Data overrides; the data of all overrides matching a namespace is merged (in the specified order) over the template data
+	Overrides []DataOverrideApplyConfiguration `json:"overrides,omitempty"`
+	// Deletion policy; defines what happens to the distributed secrets if the ClusterSecret is deleted,
+	// or if a namespace is no longer selected; one of ('Delete', 'Orphan'), defaults to 'Delete'
+	DeletionPolicy *corecssapcomv1alpha1.DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Conflict policy; defines what happens if a secret with the target name already exists in a selected namespace,
+	// but is not managed by the ClusterSecret; one of ('Fail', 'Skip', 'Adopt'), defaults to 'Fail'
+	ConflictPolicy *corecssapcomv1alpha1.ConflictPolicy `json:"conflictPolicy,omitempty"`
 }
 
 // ClusterSecretSpecApplyConfiguration constructs a declarative configuration of the ClusterSecretSpec type for use with
@@ -36,6 +50,22 @@ func (b *ClusterSecretSpecApplyConfiguration) WithNamespaceSelector(value *v1.La
 	return b
 }
 
+// WithNamespaces sets the Namespaces field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespaces field is set to the value of the last call.
+func (b *ClusterSecretSpecApplyConfiguration) WithNamespaces(value *NamespaceNamesApplyConfiguration) *ClusterSecretSpecApplyConfiguration {
+	b.Namespaces = value
+	return b
+}
+
+// WithSourceRef sets the SourceRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SourceRef field is set to the value of the last call.
+func (b *ClusterSecretSpecApplyConfiguration) WithSourceRef(value *SecretReferenceApplyConfiguration) *ClusterSecretSpecApplyConfiguration {
+	b.SourceRef = value
+	return b
+}
+
 // WithTemplate sets the Template field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Template field is set to the value of the last call.
@@ -43,3 +73,32 @@ func (b *ClusterSecretSpecApplyConfiguration) WithTemplate(value *SecretTemplate
 	b.Template = value
 	return b
 }
+
+// WithOverrides adds the given value to the Overrides field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Overrides field.
+func (b *ClusterSecretSpecApplyConfiguration) WithOverrides(values ...*DataOverrideApplyConfiguration) *ClusterSecretSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithOverrides")
+		}
+		b.Overrides = append(b.Overrides, *values[i])
+	}
+	return b
+}
+
+// WithDeletionPolicy sets the DeletionPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeletionPolicy field is set to the value of the last call.
+func (b *ClusterSecretSpecApplyConfiguration) WithDeletionPolicy(value corecssapcomv1alpha1.DeletionPolicy) *ClusterSecretSpecApplyConfiguration {
+	b.DeletionPolicy = &value
+	return b
+}
+
+// WithConflictPolicy sets the ConflictPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConflictPolicy field is set to the value of the last call.
+func (b *ClusterSecretSpecApplyConfiguration) WithConflictPolicy(value corecssapcomv1alpha1.ConflictPolicy) *ClusterSecretSpecApplyConfiguration {
+	b.ConflictPolicy = &value
+	return b
+}
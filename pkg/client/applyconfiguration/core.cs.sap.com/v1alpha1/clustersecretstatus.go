@@ -18,6 +18,18 @@ type ClusterSecretStatusApplyConfiguration struct {
 	State *string `json:"state,omitempty"`
 	// State expressed as conditions (for usage with kubectl wait et al.)
 	Conditions []ClusterSecretConditionApplyConfiguration `json:"conditions,omitempty"`
+	// Number of namespaces selected by the ClusterSecret
+	MatchedNamespaces *int32 `json:"matchedNamespaces,omitempty"`
+	// Number of selected namespaces containing an up-to-date secret
+	SyncedNamespaces *int32 `json:"syncedNamespaces,omitempty"`
+	// Number of namespaces where reconciling the secret failed
+	FailedNamespaces *int32 `json:"failedNamespaces,omitempty"`
+	// Failing namespaces and according error messages (bounded; if there are more failing namespaces than listed here, the list is truncated)
+	Failures []NamespaceFailureApplyConfiguration `json:"failures,omitempty"`
+	// Namespaces containing a secret with the target name which is not managed by the ClusterSecret
+	ConflictingNamespaces []string `json:"conflictingNamespaces,omitempty"`
+	// Number of namespaces containing a managed secret which was modified by someone else (as detected by the last audit, if auditing is enabled)
+	DriftedNamespaces *int32 `json:"driftedNamespaces,omitempty"`
 }
 
 // ClusterSecretStatusApplyConfiguration constructs a declarative configuration of the ClusterSecretStatus type for use with
@@ -54,3 +66,58 @@ func (b *ClusterSecretStatusApplyConfiguration) WithConditions(values ...*Cluste
 	}
 	return b
 }
+
+// WithMatchedNamespaces sets the MatchedNamespaces field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MatchedNamespaces field is set to the value of the last call.
+func (b *ClusterSecretStatusApplyConfiguration) WithMatchedNamespaces(value int32) *ClusterSecretStatusApplyConfiguration {
+	b.MatchedNamespaces = &value
+	return b
+}
+
+// WithSyncedNamespaces sets the SyncedNamespaces field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SyncedNamespaces field is set to the value of the last call.
+func (b *ClusterSecretStatusApplyConfiguration) WithSyncedNamespaces(value int32) *ClusterSecretStatusApplyConfiguration {
+	b.SyncedNamespaces = &value
+	return b
+}
+
+// WithFailedNamespaces sets the FailedNamespaces field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailedNamespaces field is set to the value of the last call.
+func (b *ClusterSecretStatusApplyConfiguration) WithFailedNamespaces(value int32) *ClusterSecretStatusApplyConfiguration {
+	b.FailedNamespaces = &value
+	return b
+}
+
+// WithFailures adds the given value to the Failures field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Failures field.
+func (b *ClusterSecretStatusApplyConfiguration) WithFailures(values ...*NamespaceFailureApplyConfiguration) *ClusterSecretStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithFailures")
+		}
+		b.Failures = append(b.Failures, *values[i])
+	}
+	return b
+}
+
+// WithConflictingNamespaces adds the given value to the ConflictingNamespaces field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ConflictingNamespaces field.
+func (b *ClusterSecretStatusApplyConfiguration) WithConflictingNamespaces(values ...string) *ClusterSecretStatusApplyConfiguration {
+	for i := range values {
+		b.ConflictingNamespaces = append(b.ConflictingNamespaces, values[i])
+	}
+	return b
+}
+
+// WithDriftedNamespaces sets the DriftedNamespaces field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DriftedNamespaces field is set to the value of the last call.
+func (b *ClusterSecretStatusApplyConfiguration) WithDriftedNamespaces(value int32) *ClusterSecretStatusApplyConfiguration {
+	b.DriftedNamespaces = &value
+	return b
+}
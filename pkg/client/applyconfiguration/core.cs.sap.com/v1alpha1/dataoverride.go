@@ -0,0 +1,51 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// DataOverrideApplyConfiguration represents a declarative configuration of the DataOverride type for use
+// with apply.
+//
+// DataOverride defines data which overrides the template data in certain namespaces
+type DataOverrideApplyConfiguration struct {
+	// Namespace selector; defines to which namespaces the override applies
+	NamespaceSelector *v1.LabelSelectorApplyConfiguration `json:"namespaceSelector,omitempty"`
+	// Secret data as base64 encoded raw data
+	Data map[string][]byte `json:"data,omitempty"`
+}
+
+// DataOverrideApplyConfiguration constructs a declarative configuration of the DataOverride type for use with
+// apply.
+func DataOverride() *DataOverrideApplyConfiguration {
+	return &DataOverrideApplyConfiguration{}
+}
+
+// WithNamespaceSelector sets the NamespaceSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NamespaceSelector field is set to the value of the last call.
+func (b *DataOverrideApplyConfiguration) WithNamespaceSelector(value *v1.LabelSelectorApplyConfiguration) *DataOverrideApplyConfiguration {
+	b.NamespaceSelector = value
+	return b
+}
+
+// WithData puts the entries into the Data field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Data field,
+// overwriting an existing map entries in Data field with the same key.
+func (b *DataOverrideApplyConfiguration) WithData(entries map[string][]byte) *DataOverrideApplyConfiguration {
+	if b.Data == nil && len(entries) > 0 {
+		b.Data = make(map[string][]byte, len(entries))
+	}
+	for k, v := range entries {
+		b.Data[k] = v
+	}
+	return b
+}
@@ -0,0 +1,41 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// NamespaceFailureApplyConfiguration represents a declarative configuration of the NamespaceFailure type for use
+// with apply.
+//
+// NamespaceFailure describes why reconciling the secret in a certain namespace failed
+type NamespaceFailureApplyConfiguration struct {
+	// Namespace name
+	Namespace *string `json:"namespace,omitempty"`
+	// Error message
+	Message *string `json:"message,omitempty"`
+}
+
+// NamespaceFailureApplyConfiguration constructs a declarative configuration of the NamespaceFailure type for use with
+// apply.
+func NamespaceFailure() *NamespaceFailureApplyConfiguration {
+	return &NamespaceFailureApplyConfiguration{}
+}
+
+// WithNamespace sets the Namespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespace field is set to the value of the last call.
+func (b *NamespaceFailureApplyConfiguration) WithNamespace(value string) *NamespaceFailureApplyConfiguration {
+	b.Namespace = &value
+	return b
+}
+
+// WithMessage sets the Message field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Message field is set to the value of the last call.
+func (b *NamespaceFailureApplyConfiguration) WithMessage(value string) *NamespaceFailureApplyConfiguration {
+	b.Message = &value
+	return b
+}
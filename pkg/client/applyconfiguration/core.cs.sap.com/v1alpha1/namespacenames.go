@@ -0,0 +1,45 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// NamespaceNamesApplyConfiguration represents a declarative configuration of the NamespaceNames type for use
+// with apply.
+//
+// NamespaceNames defines namespaces by their names; entries may be exact names, or glob patterns (such as team-*)
+type NamespaceNamesApplyConfiguration struct {
+	// Namespaces to be included; if empty, all namespaces are included
+	Include []string `json:"include,omitempty"`
+	// Namespaces to be excluded; takes precedence over include
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// NamespaceNamesApplyConfiguration constructs a declarative configuration of the NamespaceNames type for use with
+// apply.
+func NamespaceNames() *NamespaceNamesApplyConfiguration {
+	return &NamespaceNamesApplyConfiguration{}
+}
+
+// WithInclude adds the given value to the Include field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Include field.
+func (b *NamespaceNamesApplyConfiguration) WithInclude(values ...string) *NamespaceNamesApplyConfiguration {
+	for i := range values {
+		b.Include = append(b.Include, values[i])
+	}
+	return b
+}
+
+// WithExclude adds the given value to the Exclude field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Exclude field.
+func (b *NamespaceNamesApplyConfiguration) WithExclude(values ...string) *NamespaceNamesApplyConfiguration {
+	for i := range values {
+		b.Exclude = append(b.Exclude, values[i])
+	}
+	return b
+}
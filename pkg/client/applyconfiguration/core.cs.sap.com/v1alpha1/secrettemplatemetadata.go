@@ -0,0 +1,53 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// SecretTemplateMetadataApplyConfiguration represents a declarative configuration of the SecretTemplateMetadata type for use
+// with apply.
+//
+// SecretTemplateMetadata defines labels and annotations of the managed secrets
+type SecretTemplateMetadataApplyConfiguration struct {
+	// Labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// SecretTemplateMetadataApplyConfiguration constructs a declarative configuration of the SecretTemplateMetadata type for use with
+// apply.
+func SecretTemplateMetadata() *SecretTemplateMetadataApplyConfiguration {
+	return &SecretTemplateMetadataApplyConfiguration{}
+}
+
+// WithLabels puts the entries into the Labels field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Labels field,
+// overwriting an existing map entries in Labels field with the same key.
+func (b *SecretTemplateMetadataApplyConfiguration) WithLabels(entries map[string]string) *SecretTemplateMetadataApplyConfiguration {
+	if b.Labels == nil && len(entries) > 0 {
+		b.Labels = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Labels[k] = v
+	}
+	return b
+}
+
+// WithAnnotations puts the entries into the Annotations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Annotations field,
+// overwriting an existing map entries in Annotations field with the same key.
+func (b *SecretTemplateMetadataApplyConfiguration) WithAnnotations(entries map[string]string) *SecretTemplateMetadataApplyConfiguration {
+	if b.Annotations == nil && len(entries) > 0 {
+		b.Annotations = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Annotations[k] = v
+	}
+	return b
+}
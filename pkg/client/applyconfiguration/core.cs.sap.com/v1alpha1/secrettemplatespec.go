@@ -16,12 +16,20 @@ import (
 //
 // SecretTemplateSpec defines how the managed secrets should look like
 type SecretTemplateSpecApplyConfiguration struct {
+	// Secret name; defaults to the name of the ClusterSecret
+	Name *string `json:"name,omitempty"`
+	// Secret metadata; labels and annotations to be added to the managed secrets
+	Metadata *SecretTemplateMetadataApplyConfiguration `json:"metadata,omitempty"`
 	// Secret type
 	Type *v1.SecretType `json:"type,omitempty"`
 	// Secret data as base64 encoded raw data
 	Data map[string][]byte `json:"data,omitempty"`
 	// Secret data as string
 	StringData map[string]string `json:"stringData,omitempty"`
+	// Whether data values are Go templates, to be rendered individually for each target namespace
+	Templated *bool `json:"templated,omitempty"`
+	// Whether the managed secrets are immutable; changes of the data cause the secrets to be deleted and recreated
+	Immutable *bool `json:"immutable,omitempty"`
 }
 
 // SecretTemplateSpecApplyConfiguration constructs a declarative configuration of the SecretTemplateSpec type for use with
@@ -30,6 +38,22 @@ func SecretTemplateSpec() *SecretTemplateSpecApplyConfiguration {
 	return &SecretTemplateSpecApplyConfiguration{}
 }
 
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *SecretTemplateSpecApplyConfiguration) WithName(value string) *SecretTemplateSpecApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithMetadata sets the Metadata field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Metadata field is set to the value of the last call.
+func (b *SecretTemplateSpecApplyConfiguration) WithMetadata(value *SecretTemplateMetadataApplyConfiguration) *SecretTemplateSpecApplyConfiguration {
+	b.Metadata = value
+	return b
+}
+
 // WithType sets the Type field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Type field is set to the value of the last call.
@@ -65,3 +89,19 @@ func (b *SecretTemplateSpecApplyConfiguration) WithStringData(entries map[string
 	}
 	return b
 }
+
+// WithTemplated sets the Templated field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Templated field is set to the value of the last call.
+func (b *SecretTemplateSpecApplyConfiguration) WithTemplated(value bool) *SecretTemplateSpecApplyConfiguration {
+	b.Templated = &value
+	return b
+}
+
+// WithImmutable sets the Immutable field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Immutable field is set to the value of the last call.
+func (b *SecretTemplateSpecApplyConfiguration) WithImmutable(value bool) *SecretTemplateSpecApplyConfiguration {
+	b.Immutable = &value
+	return b
+}
@@ -29,6 +29,16 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &corecssapcomv1alpha1.ClusterSecretSpecApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ClusterSecretStatus"):
 		return &corecssapcomv1alpha1.ClusterSecretStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("DataOverride"):
+		return &corecssapcomv1alpha1.DataOverrideApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("NamespaceFailure"):
+		return &corecssapcomv1alpha1.NamespaceFailureApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("NamespaceNames"):
+		return &corecssapcomv1alpha1.NamespaceNamesApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SecretReference"):
+		return &corecssapcomv1alpha1.SecretReferenceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SecretTemplateMetadata"):
+		return &corecssapcomv1alpha1.SecretTemplateMetadataApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("SecretTemplateSpec"):
 		return &corecssapcomv1alpha1.SecretTemplateSpecApplyConfiguration{}
 
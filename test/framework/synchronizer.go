@@ -91,6 +91,10 @@ func (s *Synchronizer) WaitUntilSynced() {
 				if item.resourceVersion != "-" && objmeta.GetUID() == uid && s.compareResourceversion(objmeta.GetResourceVersion(), item.resourceVersion) >= 0 {
 					klog.V(3).Infof("synchronize (wait): clearing creation/update %s (have: %s)", item, objmeta.GetResourceVersion())
 					delete(s.items, uid)
+				} else if item.resourceVersion == "-" && objmeta.GetUID() != uid {
+					// the object was deleted and recreated with the same name
+					klog.V(3).Infof("synchronize (wait): clearing deletion %s (recreated)", item)
+					delete(s.items, uid)
 				} else {
 					klog.V(3).Infof("synchronize (wait): not clearing %s (have: %s)", item, objmeta.GetResourceVersion())
 				}
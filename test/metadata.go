@@ -0,0 +1,165 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and clustersecret-operator contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package test
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/metadata"
+	kubetesting "k8s.io/client-go/testing"
+)
+
+// Return a metadata client serving the objects of the kubernetes client of the environment (as PartialObjectMetadata)
+func (env *Environment) MetadataClient() metadata.Interface {
+	return NewMetadataClient(env.KubernetesClient().Tracker())
+}
+
+// Return a (read-only) metadata client serving the objects of the given tracker (as PartialObjectMetadata),
+// the same way as the api server does for metadata requests; the objects must be known to the kubernetes scheme
+func NewMetadataClient(tracker kubetesting.ObjectTracker) metadata.Interface {
+	return &metadataClient{tracker: tracker}
+}
+
+type metadataClient struct {
+	tracker kubetesting.ObjectTracker
+}
+
+type metadataResourceClient struct {
+	tracker   kubetesting.ObjectTracker
+	resource  schema.GroupVersionResource
+	namespace string
+}
+
+var _ metadata.Interface = &metadataClient{}
+
+// note: the object tracker does not support watch list semantics (i.e. streaming the initial list as watch events)
+func (c *metadataClient) IsWatchListSemanticsUnSupported() bool {
+	return true
+}
+
+func (c *metadataClient) Resource(resource schema.GroupVersionResource) metadata.Getter {
+	return &metadataResourceClient{tracker: c.tracker, resource: resource}
+}
+
+func (c *metadataResourceClient) Namespace(namespace string) metadata.ResourceInterface {
+	return &metadataResourceClient{tracker: c.tracker, resource: c.resource, namespace: namespace}
+}
+
+func (c *metadataResourceClient) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*metav1.PartialObjectMetadata, error) {
+	if len(subresources) > 0 {
+		return nil, fmt.Errorf("subresources not supported")
+	}
+	obj, err := c.tracker.Get(c.resource, c.namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return toPartialObjectMetadata(obj)
+}
+
+func (c *metadataResourceClient) List(ctx context.Context, options metav1.ListOptions) (*metav1.PartialObjectMetadataList, error) {
+	gvk, err := c.kind()
+	if err != nil {
+		return nil, err
+	}
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := c.tracker.List(c.resource, gvk, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	listMeta, err := meta.ListAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return nil, err
+	}
+	list := &metav1.PartialObjectMetadataList{
+		TypeMeta: metav1.TypeMeta{APIVersion: metav1.SchemeGroupVersion.String(), Kind: "PartialObjectMetadataList"},
+		ListMeta: metav1.ListMeta{ResourceVersion: listMeta.GetResourceVersion()},
+	}
+	for _, item := range items {
+		partialObjectMetadata, err := toPartialObjectMetadata(item)
+		if err != nil {
+			return nil, err
+		}
+		if selector.Matches(labels.Set(partialObjectMetadata.Labels)) {
+			list.Items = append(list.Items, *partialObjectMetadata)
+		}
+	}
+	return list, nil
+}
+
+func (c *metadataResourceClient) Watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	w, err := c.tracker.Watch(c.resource, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		if event.Type == watch.Error || event.Type == watch.Bookmark {
+			return event, true
+		}
+		partialObjectMetadata, err := toPartialObjectMetadata(event.Object)
+		if err != nil {
+			return watch.Event{Type: watch.Error, Object: &metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}}, true
+		}
+		event.Object = partialObjectMetadata
+		return event, selector.Matches(labels.Set(partialObjectMetadata.Labels))
+	}), nil
+}
+
+func (c *metadataResourceClient) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	return fmt.Errorf("not supported by this metadata client")
+}
+
+func (c *metadataResourceClient) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	return fmt.Errorf("not supported by this metadata client")
+}
+
+func (c *metadataResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*metav1.PartialObjectMetadata, error) {
+	return nil, fmt.Errorf("not supported by this metadata client")
+}
+
+// return the kind of the resource of this client (as known to the kubernetes scheme)
+func (c *metadataResourceClient) kind() (schema.GroupVersionKind, error) {
+	for gvk := range kubescheme.Scheme.AllKnownTypes() {
+		if plural, _ := meta.UnsafeGuessKindToResource(gvk); plural == c.resource {
+			return gvk, nil
+		}
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("resource %s not known", c.resource)
+}
+
+func toPartialObjectMetadata(obj runtime.Object) (*metav1.PartialObjectMetadata, error) {
+	accessor, ok := obj.(metav1.ObjectMetaAccessor)
+	if !ok {
+		return nil, fmt.Errorf("object of type %T has no object metadata", obj)
+	}
+	objectMeta, ok := accessor.GetObjectMeta().(*metav1.ObjectMeta)
+	if !ok {
+		return nil, fmt.Errorf("object of type %T has unexpected object metadata", obj)
+	}
+	return &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: metav1.SchemeGroupVersion.String(), Kind: "PartialObjectMetadata"},
+		ObjectMeta: *objectMeta.DeepCopy(),
+	}, nil
+}